@@ -0,0 +1,152 @@
+package faas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/obscurelyme/jeeves/config"
+	"github.com/spf13/cobra"
+)
+
+var aliasVersion string
+var aliasDescription string
+var aliasFaasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage the aliases of a FaaS resource",
+	Long:  "Create, update and delete aliases pointing at published versions of a FaaS resource",
+}
+
+var createAliasCmd = &cobra.Command{
+	Use:   "create NAME ALIAS",
+	Short: "Creates an alias",
+	Long:  "Creates an alias pointing at a published version, IE: jeeves faas alias create my-function live --version 3",
+	Args:  cobra.ExactArgs(2),
+	RunE:  createAliasCmdHandler,
+}
+
+var updateAliasCmd = &cobra.Command{
+	Use:   "update NAME ALIAS",
+	Short: "Points an alias at another version",
+	Long:  "Points an alias at another published version, clearing any weighted routing",
+	Args:  cobra.ExactArgs(2),
+	RunE:  updateAliasCmdHandler,
+}
+
+var deleteAliasCmd = &cobra.Command{
+	Use:   "delete NAME ALIAS",
+	Short: "Deletes an alias",
+	Long:  "Deletes an alias, the versions it pointed at are kept",
+	Args:  cobra.ExactArgs(2),
+	RunE:  deleteAliasCmdHandler,
+}
+
+func init() {
+	aliasFaasCmd.PersistentFlags().StringVar(&profile, "profile", "default", "AWS Profile to work with")
+	createAliasCmd.PersistentFlags().StringVar(&aliasVersion, "version", "", "Version the alias points at (required)")
+	createAliasCmd.PersistentFlags().StringVar(&aliasDescription, "description", "", "Description of the alias")
+	updateAliasCmd.PersistentFlags().StringVar(&aliasVersion, "version", "", "Version the alias points at (required)")
+	updateAliasCmd.PersistentFlags().StringVar(&aliasDescription, "description", "", "Description of the alias")
+
+	addForceFlag(deleteAliasCmd)
+
+	aliasFaasCmd.AddCommand(createAliasCmd)
+	aliasFaasCmd.AddCommand(updateAliasCmd)
+	aliasFaasCmd.AddCommand(deleteAliasCmd)
+}
+
+func createAliasCmdHandler(cmd *cobra.Command, args []string) error {
+	if aliasVersion == "" {
+		return errors.New("version is a required flag --version [VERSION]")
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	client := lambda.NewFromConfig(cfg)
+	_, err = client.CreateAlias(context.TODO(), &lambda.CreateAliasInput{
+		FunctionName:    &args[0],
+		Name:            &args[1],
+		FunctionVersion: &aliasVersion,
+		Description:     optionalString(aliasDescription),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Alias %s of %s now points at version %s\n", args[1], args[0], aliasVersion)
+	return nil
+}
+
+func updateAliasCmdHandler(cmd *cobra.Command, args []string) error {
+	if aliasVersion == "" {
+		return errors.New("version is a required flag --version [VERSION]")
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	err = UpdateFaaSAlias(cfg, args[0], args[1], aliasVersion, nil)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Alias %s of %s now points at version %s\n", args[1], args[0], aliasVersion)
+	return nil
+}
+
+func deleteAliasCmdHandler(cmd *cobra.Command, args []string) error {
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	err = requireManaged(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	client := lambda.NewFromConfig(cfg)
+	_, err = client.DeleteAlias(context.TODO(), &lambda.DeleteAliasInput{
+		FunctionName: &args[0],
+		Name:         &args[1],
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Alias %s of %s was deleted\n", args[1], args[0])
+	return nil
+}
+
+// Points the alias at the given version. Additional version weights route a
+// share of the traffic to other versions, passing nil clears them.
+func UpdateFaaSAlias(cfg aws.Config, name string, alias string, version string, weights map[string]float64) error {
+	client := lambda.NewFromConfig(cfg)
+
+	if weights == nil {
+		weights = map[string]float64{}
+	}
+
+	_, err := client.UpdateAlias(context.TODO(), &lambda.UpdateAliasInput{
+		FunctionName:    &name,
+		Name:            &alias,
+		FunctionVersion: &version,
+		Description:     optionalString(aliasDescription),
+		RoutingConfig: &lambdaTypes.AliasRoutingConfiguration{
+			AdditionalVersionWeights: weights,
+		},
+	})
+
+	return err
+}
@@ -0,0 +1,150 @@
+package faas
+
+import (
+	"context"
+	"debug/elf"
+	"debug/macho"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/obscurelyme/jeeves/types"
+	"github.com/obscurelyme/jeeves/utils/archive"
+)
+
+// A native file built for a different architecture than the function runs on
+type NativeMismatch struct {
+	Path string
+	// Architecture the file was built for, IE: x86_64 or darwin
+	Built string
+}
+
+// Resolves the architecture of the flag, falling back to architecture of faas.yaml.
+// Returns an empty architecture when neither is set.
+func resolveArchitecture(flag string, configured string, runtime string) (lambdaTypes.Architecture, error) {
+	value := flag
+	if value == "" {
+		value = configured
+	}
+	if value == "" {
+		return "", nil
+	}
+
+	architecture, err := types.ParseArchitecture(value)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := types.LookupRuntime(runtime)
+	if err == nil && !info.SupportsArchitecture(architecture) {
+		return "", fmt.Errorf("the %s runtime does not support the %s architecture", runtime, architecture)
+	}
+
+	return architecture, nil
+}
+
+// Architecture of the deployed function, DEFAULT_ARCHITECTURE when it does not exist yet
+func functionArchitecture(cfg aws.Config, name string) (lambdaTypes.Architecture, error) {
+	client := lambda.NewFromConfig(cfg)
+
+	configuration, err := client.GetFunctionConfiguration(context.TODO(), &lambda.GetFunctionConfigurationInput{
+		FunctionName: &name,
+	})
+	if isResourceNotFound(err) {
+		return types.DEFAULT_ARCHITECTURE, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if len(configuration.Architectures) == 0 {
+		return lambdaTypes.ArchitectureX8664, nil
+	}
+	return configuration.Architectures[0], nil
+}
+
+// Prints a warning for every native file of the runtime's build output which was
+// built for another architecture. The build output may not exist yet, IE: before the first build.
+func warnNativeMismatches(runtime string, architecture lambdaTypes.Architecture) {
+	entries, err := deploymentEntries(runtime)
+	if err != nil {
+		return
+	}
+
+	mismatches, err := NativeMismatches(entries, architecture)
+	if err != nil {
+		return
+	}
+
+	for _, mismatch := range mismatches {
+		fmt.Fprintf(os.Stderr, "WARNING: %s was built for %s but the function runs on %s\n", mismatch.Path, mismatch.Built, architecture)
+	}
+}
+
+// Finds the native files of the entries, executables named bootstrap, shared
+// objects and node addons, which were not built for Linux on the architecture.
+func NativeMismatches(entries []archive.Entry, architecture lambdaTypes.Architecture) ([]NativeMismatch, error) {
+	mismatches := []NativeMismatch{}
+
+	for _, entry := range entries {
+		err := filepath.WalkDir(entry.Source, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !isNativeFile(d.Name()) {
+				return nil
+			}
+
+			built, ok := nativeArchitecture(path)
+			if ok && built != string(architecture) {
+				mismatches = append(mismatches, NativeMismatch{Path: path, Built: built})
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	return mismatches, nil
+}
+
+// IE: bootstrap, _cffi.cpython-312-x86_64-linux-gnu.so, libssl.so.3 or sharp.node
+func isNativeFile(name string) bool {
+	return name == "bootstrap" ||
+		strings.HasSuffix(name, ".so") ||
+		strings.Contains(name, ".so.") ||
+		strings.HasSuffix(name, ".node")
+}
+
+// Architecture the native file was built for, false when the file is not a binary
+func nativeArchitecture(path string) (string, bool) {
+	if file, err := elf.Open(path); err == nil {
+		defer file.Close()
+
+		switch file.Machine {
+		case elf.EM_AARCH64:
+			return string(lambdaTypes.ArchitectureArm64), true
+		case elf.EM_X86_64:
+			return string(lambdaTypes.ArchitectureX8664), true
+		}
+		return strings.ToLower(strings.TrimPrefix(file.Machine.String(), "EM_")), true
+	}
+
+	// NOTE: Lambda only runs Linux binaries, IE: a node addon installed on a Mac
+	if file, err := macho.Open(path); err == nil {
+		file.Close()
+		return "darwin", true
+	}
+	if file, err := macho.OpenFat(path); err == nil {
+		file.Close()
+		return "darwin", true
+	}
+
+	return "", false
+}
@@ -0,0 +1,98 @@
+package faas
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/obscurelyme/jeeves/utils/archive"
+)
+
+// Writes the 64 byte header of a 64-bit little endian ELF file for the machine
+func writeElf(t *testing.T, path string, machine elf.Machine) {
+	header := make([]byte, 64)
+	copy(header, elf.ELFMAG)
+	header[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	binary.LittleEndian.PutUint16(header[16:], uint16(elf.ET_DYN))
+	binary.LittleEndian.PutUint16(header[18:], uint16(machine))
+	binary.LittleEndian.PutUint32(header[20:], uint32(elf.EV_CURRENT))
+	binary.LittleEndian.PutUint16(header[52:], 64)
+
+	os.MkdirAll(filepath.Dir(path), 0755)
+	err := os.WriteFile(path, header, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNativeMismatches(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeElf(t, filepath.Join(tmpDir, "node_modules/sharp/build/sharp.node"), elf.EM_X86_64)
+	writeElf(t, filepath.Join(tmpDir, "node_modules/bcrypt/bcrypt.node"), elf.EM_AARCH64)
+	os.WriteFile(filepath.Join(tmpDir, "node_modules/fake.so"), []byte("not a binary"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "node_modules/index.js"), []byte("module.exports = {}"), 0644)
+
+	entries := []archive.Entry{
+		{Source: filepath.Join(tmpDir, "node_modules"), Target: "node_modules"},
+		{Source: filepath.Join(tmpDir, "dist"), Target: "dist"},
+	}
+
+	t.Run("should report native files built for another architecture", func(t *testing.T) {
+		mismatches, err := NativeMismatches(entries, lambdaTypes.ArchitectureArm64)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if len(mismatches) != 1 || filepath.Base(mismatches[0].Path) != "sharp.node" || mismatches[0].Built != "x86_64" {
+			t.Errorf("expected sharp.node built for x86_64, but received %+v", mismatches)
+		}
+	})
+
+	t.Run("should report nothing when the architectures match", func(t *testing.T) {
+		os.Remove(filepath.Join(tmpDir, "node_modules/bcrypt/bcrypt.node"))
+
+		mismatches, err := NativeMismatches(entries, lambdaTypes.ArchitectureX8664)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if len(mismatches) != 0 {
+			t.Errorf("expected no mismatches, but received %+v", mismatches)
+		}
+	})
+}
+
+func TestResolveArchitecture(t *testing.T) {
+	t.Run("should prefer the flag over faas.yaml", func(t *testing.T) {
+		architecture, err := resolveArchitecture("amd64", "arm64", "nodejs22.x")
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if architecture != lambdaTypes.ArchitectureX8664 {
+			t.Errorf("expected x86_64, but received %s", architecture)
+		}
+	})
+
+	t.Run("should be empty when nothing is selected", func(t *testing.T) {
+		architecture, _ := resolveArchitecture("", "", "nodejs22.x")
+		if architecture != "" {
+			t.Errorf("expected no architecture, but received %s", architecture)
+		}
+	})
+
+	t.Run("should reject unknown architectures", func(t *testing.T) {
+		_, err := resolveArchitecture("", "mips", "nodejs22.x")
+		if err == nil {
+			t.Errorf("expected an error for an unknown architecture")
+		}
+	})
+}
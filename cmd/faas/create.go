@@ -2,33 +2,31 @@ package faas
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	"github.com/manifoldco/promptui"
 	"github.com/obscurelyme/jeeves/config"
 	"github.com/obscurelyme/jeeves/types"
+	"github.com/obscurelyme/jeeves/utils"
+	"github.com/obscurelyme/jeeves/utils/repository"
+	"github.com/obscurelyme/jeeves/utils/tags"
 	"github.com/spf13/cobra"
 )
 
 // Name of the S3 bucket which holds all example lambda zips
 var S3_BUCKET_NAME string = "example-lambda-apps"
 
-// Owner of the template repos
-var TEMPLATE_REPO_OWNER string = "obscurelyme"
-
 // Basic execution policy for Lambda Functions.
 var LAMBDA_BASIC_EXECUTION_ROLE string = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
 
-var CREATE_LAMBDA_REPOSITORY string = "create-lambda-repository"
-
 var TRUST_POLICY_DOC string = `{
 	"Version": "2012-10-17",
   "Statement": [
@@ -44,11 +42,21 @@ var TRUST_POLICY_DOC string = `{
 
 var BASIC_LAMBDA_POLICY_ARN = "arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
 
+var repositoryProvider string
+var createArchitecture string
 var createFaasCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create and provison new FaaS resources",
-	Long:  "Opens a prompt to create and provision brand new FaaS functions",
-	RunE:  createFassCmdHandler,
+	Long: `Opens a prompt to create and provision brand new FaaS functions.
+The source repository is provisioned with the provider set under Repository
+in .jeeves.yaml: github, gitlab, lambda (default) or none.`,
+	RunE: createFassCmdHandler,
+}
+
+func init() {
+	createFaasCmd.PersistentFlags().StringVar(&profile, "profile", "default", "AWS Profile to work with")
+	createFaasCmd.PersistentFlags().StringVar(&repositoryProvider, "repository", "", "Repository provider, overrides .jeeves.yaml: github, gitlab, lambda or none")
+	createFaasCmd.PersistentFlags().StringVar(&createArchitecture, "arch", "", "Architecture of the function, arm64 or x86_64, defaults to function.architecture of faas.yaml or arm64")
 }
 
 var promptTemplate = &promptui.PromptTemplates{
@@ -78,6 +86,20 @@ func createFassCmdHandler(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	warnRuntimeDeprecation(string(runtimeSelection.AWSRuntime))
+
+	// NOTE: faas.yaml is optional, create may run outside of a FaaS project
+	configured := ""
+	if faasConfig, err := ReadLambdaConfig(); err == nil {
+		configured = faasConfig.GetString("function.architecture")
+	}
+	architecture, err := resolveArchitecture(createArchitecture, configured, string(runtimeSelection.AWSRuntime))
+	if err != nil {
+		return err
+	}
+	if architecture == "" {
+		architecture = types.DEFAULT_ARCHITECTURE
+	}
 
 	confirmed, err := promptConfirm(functionName)
 	if err != nil {
@@ -93,6 +115,7 @@ func createFassCmdHandler(cmd *cobra.Command, args []string) error {
 	input := types.CreateFaaSResourceInput{
 		FunctionName: functionName,
 		Runtime:      &runtimeSelection,
+		Architecture: architecture,
 	}
 
 	err = ProvisionFaasRepo(input)
@@ -119,16 +142,24 @@ func promptInput() (string, error) {
 	return prompt.Run()
 }
 
+// Prints a warning when the runtime is deprecated or nears its deprecation date
+func warnRuntimeDeprecation(runtime string) {
+	if warning := types.RuntimeDeprecationWarning(runtime); warning != "" {
+		fmt.Fprintf(os.Stderr, "WARNING: %s\n", warning)
+	}
+}
+
 func promptLambdaRuntimeSelect() (types.LambdaRuntime, error) {
+	options := types.CreateSelectionOptions()
 	prompt := promptui.Select{
 		Label:     "Function Runtime: ",
 		Templates: selectTemplate,
-		Items:     types.RuntimeSelectionOptions,
+		Items:     options,
 	}
 
 	index, _, err := prompt.Run()
 
-	return types.RuntimeSelectionOptions[index], err
+	return options[index], err
 }
 
 func promptConfirm(name string) (string, error) {
@@ -142,41 +173,51 @@ func promptConfirm(name string) (string, error) {
 	return prompt.Run()
 }
 
+// Provisions the source repository of the FaaS resource with the configured repository provider
 func ProvisionFaasRepo(input types.CreateFaaSResourceInput) error {
 	loader := &config.AWSConfigLoader{}
 	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
 
+	provider, err := newRepositoryProvider(cfg)
 	if err != nil {
 		return err
 	}
 
-	client := lambda.NewFromConfig(cfg)
-	creds, _ := cfg.Credentials.Retrieve(context.TODO())
-	functionName := fmt.Sprintf("arn:aws:lambda:%s:%s:function:%s", cfg.Region, creds.AccountID, CREATE_LAMBDA_REPOSITORY)
-	data, _ := json.Marshal(&types.Payload{
-		TemplateRepo:          input.Runtime.TemplateRepo,
-		TemplateOwner:         TEMPLATE_REPO_OWNER,
-		Owner:                 TEMPLATE_REPO_OWNER,
-		RepositoryName:        fmt.Sprintf("%v.lambda", input.FunctionName),
-		RepositoryDescription: "",
-		Visibility:            "public",
-	})
+	resourceTags, err := tags.New(cfg, input.FunctionName)
+	if err != nil {
+		return err
+	}
 
-	output, err := client.Invoke(context.TODO(), &lambda.InvokeInput{
-		FunctionName: &functionName,
-		Payload:      data,
+	repo, err := provider.Create(&repository.CreateInput{
+		Name:         RepositoryName(input.FunctionName),
+		TemplateRepo: input.Runtime.TemplateRepo,
+		Tags:         resourceTags,
 	})
-
 	if err != nil {
 		return err
 	}
 
-	if output.StatusCode == http.StatusOK {
-		fmt.Printf("Github repository for %s created!\n", input.FunctionName)
-		return nil
+	if repo.Url != "" {
+		fmt.Printf("Repository for %s created at %s\n", input.FunctionName, repo.Url)
+	}
+	return nil
+}
+
+// Name of the source repository of a FaaS resource
+func RepositoryName(functionName string) string {
+	return fmt.Sprintf("%s.lambda", functionName)
+}
+
+func newRepositoryProvider(cfg aws.Config) (repository.Provider, error) {
+	settings := utils.JeevesRepository{}
+	if utils.Jeeves != nil {
+		settings = utils.Jeeves.ConfigSettings.Repository
 	}
 
-	return fmt.Errorf("lambda failed with status code: %d", output.StatusCode)
+	return repository.New(repositoryProvider, settings, cfg)
 }
 
 // Creates the Lambda Function, this function can take some time due to having
@@ -191,6 +232,11 @@ func CreateFaasResource(input types.CreateFaaSResourceInput) error {
 	}
 	client := lambda.NewFromConfig(cfg)
 
+	input.Tags, err = tags.New(cfg, input.FunctionName)
+	if err != nil {
+		return err
+	}
+
 	var defaultTimeout int32 = 30
 	var functionCode = lambdaTypes.FunctionCode{
 		S3Bucket: &S3_BUCKET_NAME,
@@ -240,10 +286,11 @@ func CreateLambdaRole(input *types.CreateFaaSResourceInput) (string, string, err
 	roleOutput, roleErr := iamClient.CreateRole(context.TODO(), &iam.CreateRoleInput{
 		AssumeRolePolicyDocument: &TRUST_POLICY_DOC,
 		RoleName:                 &roleName,
+		Tags:                     tags.ToIAM(input.Tags),
 	})
 
 	if roleErr != nil {
-		return "", "", err
+		return "", "", roleErr
 	}
 
 	_, policyErr := iamClient.AttachRolePolicy(context.TODO(), &iam.AttachRolePolicyInput{
@@ -263,11 +310,12 @@ func TryMakeFaaSResource(client *lambda.Client, functionCode lambdaTypes.Functio
 		Code:          &functionCode,
 		FunctionName:  &input.FunctionName,
 		Role:          &roleArn,
-		Architectures: []lambdaTypes.Architecture{lambdaTypes.ArchitectureArm64},
+		Architectures: []lambdaTypes.Architecture{input.Architecture},
 		// Description: ""
 		Runtime: input.Runtime.AWSRuntime,
 		Timeout: &defaultTimeout,
 		Handler: &input.Runtime.Handler,
+		Tags:    input.Tags,
 	})
 
 	if err != nil {
@@ -0,0 +1,273 @@
+package faas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/obscurelyme/jeeves/templates"
+	"github.com/obscurelyme/jeeves/types"
+	"github.com/spf13/viper"
+)
+
+// Compose override applied on top of the compose file by faas start --debug
+const DEBUG_COMPOSE_FILE string = "docker-compose.debug.yaml"
+
+// Name of the generated IDE configurations, replaced on every faas start --debug
+const DEBUG_CONFIGURATION_NAME string = "jeeves: attach to FaaS"
+
+const VSCODE_LAUNCH_FILE string = ".vscode/launch.json"
+const JETBRAINS_RUN_FILE string = ".run/jeeves-debug.run.xml"
+
+const NODE_DEBUG_PORT int = 9229
+const JAVA_DEBUG_PORT int = 5005
+
+// NOTE: debugpy always listens within the python image, see templates/scripts/python/bootstrap.sh
+const PYTHON_DEBUG_PORT int = 5678
+
+// How the function of a runtime is started under a debugger
+type DebugTarget struct {
+	Port int
+	// Variables which enable the debugger within the runtime, IE: NODE_OPTIONS
+	Environment map[string]string
+	// Dockerfile built instead of the regular one, IE: Dockerfile.debug for Go
+	Dockerfile string
+	// Configuration attaching VS Code to Port
+	VSCode map[string]any
+	// Run configuration attaching JetBrains IDEs to Port, none when empty
+	JetBrains string
+}
+
+var debugTargets = map[types.LambdaLanguage]DebugTarget{
+	types.NodeJs: {
+		Port:        NODE_DEBUG_PORT,
+		Environment: map[string]string{"NODE_OPTIONS": fmt.Sprintf("--inspect=0.0.0.0:%d", NODE_DEBUG_PORT)},
+		VSCode: map[string]any{
+			"type":       "node",
+			"request":    "attach",
+			"address":    "localhost",
+			"port":       NODE_DEBUG_PORT,
+			"localRoot":  "${workspaceFolder}",
+			"remoteRoot": LAMBDA_TASK_ROOT,
+			"skipFiles":  []string{"<node_internals>/**"},
+		},
+		JetBrains: fmt.Sprintf(`<configuration name="%s" type="ChromiumRemoteDebugType" factoryName="Chromium Remote" host="localhost" port="%d">
+    <method v="2" />
+  </configuration>`, DEBUG_CONFIGURATION_NAME, NODE_DEBUG_PORT),
+	},
+	types.Java: {
+		Port: JAVA_DEBUG_PORT,
+		Environment: map[string]string{
+			"JAVA_TOOL_OPTIONS": fmt.Sprintf("-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=*:%d", JAVA_DEBUG_PORT),
+		},
+		VSCode: map[string]any{
+			"type":     "java",
+			"request":  "attach",
+			"hostName": "localhost",
+			"port":     JAVA_DEBUG_PORT,
+		},
+		JetBrains: fmt.Sprintf(`<configuration name="%s" type="Remote">
+    <option name="USE_SOCKET_TRANSPORT" value="true" />
+    <option name="SERVER_MODE" value="false" />
+    <option name="HOST" value="localhost" />
+    <option name="PORT" value="%d" />
+    <option name="AUTO_RESTART" value="false" />
+    <method v="2" />
+  </configuration>`, DEBUG_CONFIGURATION_NAME, JAVA_DEBUG_PORT),
+	},
+	types.Golang: {
+		Port:       templates.GO_DEBUG_PORT,
+		Dockerfile: "Dockerfile.debug",
+		VSCode: map[string]any{
+			"type":    "go",
+			"request": "attach",
+			"mode":    "remote",
+			"host":    "localhost",
+			"port":    templates.GO_DEBUG_PORT,
+			// NOTE: Dockerfile.debug builds the sources copied to /src
+			"substitutePath": []map[string]string{{"from": "${workspaceFolder}", "to": "/src"}},
+		},
+		JetBrains: fmt.Sprintf(`<configuration name="%s" type="GoRemoteDebugConfigurationType" factoryName="Go Remote" port="%d">
+    <option name="disconnectOption" value="LEAVE" />
+    <disconnect value="LEAVE" />
+    <method v="2" />
+  </configuration>`, DEBUG_CONFIGURATION_NAME, templates.GO_DEBUG_PORT),
+	},
+	types.Python: {
+		Port: PYTHON_DEBUG_PORT,
+		VSCode: map[string]any{
+			"type":    "debugpy",
+			"request": "attach",
+			"connect": map[string]any{"host": "localhost", "port": PYTHON_DEBUG_PORT},
+			"pathMappings": []map[string]string{
+				{"localRoot": "${workspaceFolder}/src", "remoteRoot": LAMBDA_TASK_ROOT},
+			},
+		},
+		// NOTE: PyCharm only attaches through its own debug server, not debugpy
+	},
+}
+
+// Finds how functions of the runtime are debugged
+func LookupDebugTarget(runtime string) (DebugTarget, error) {
+	info, err := types.LookupRuntime(runtime)
+	if err != nil {
+		return DebugTarget{}, err
+	}
+
+	target, ok := debugTargets[info.Language]
+	if !ok {
+		return DebugTarget{}, fmt.Errorf("debugging the \"%s\" runtime is not supported", runtime)
+	}
+
+	// NOTE: the override replaces variables of the lambda service, keep the options of the runtime, IE: --enable-source-maps
+	environment := map[string]string{}
+	for key, value := range target.Environment {
+		if runtimeValue, found := info.Compose.Environment[key]; found {
+			value = runtimeValue + " " + value
+		}
+		environment[key] = value
+	}
+	target.Environment = environment
+
+	return target, nil
+}
+
+// Writes the compose override and IDE configurations for debugging the function,
+// and selects the override for the following docker compose commands
+func initializeDebugFiles(faasConfig *viper.Viper, faasRuntime string, faasHandler string, faasArchitecture lambdaTypes.Architecture) (DebugTarget, error) {
+	target, err := LookupDebugTarget(faasRuntime)
+	if err != nil {
+		return target, err
+	}
+
+	if target.Dockerfile != "" {
+		env, err := dockerfileEnv(faasConfig)
+		if err != nil {
+			return target, err
+		}
+
+		dockerFile, err := templates.NewGoDebugDockerFile(&templates.NewDockerFileInput{
+			Runtime:      faasRuntime,
+			Handler:      faasHandler,
+			Architecture: faasArchitecture,
+			FilePath:     ConfigPath,
+			Env:          env,
+		})
+		if err != nil {
+			return target, err
+		}
+
+		err = writeGenerated(dockerFile.Path(), dockerFile.Content())
+		if err != nil {
+			return target, err
+		}
+	}
+
+	override, err := debugComposeOverride(target)
+	if err != nil {
+		return target, err
+	}
+
+	err = writeGenerated(filepath.Join(ConfigPath, DEBUG_COMPOSE_FILE), templates.Stamp(string(override)))
+	if err != nil {
+		return target, err
+	}
+
+	err = writeVSCodeLaunch(filepath.Join(ConfigPath, VSCODE_LAUNCH_FILE), target)
+	if err != nil {
+		// NOTE: a launch.json with comments can not be merged, the debugger still works without it
+		fmt.Fprintf(os.Stderr, "WARNING: skipped %s: %s\n", VSCODE_LAUNCH_FILE, err.Error())
+	}
+
+	if target.JetBrains != "" {
+		err = writeJetBrainsRunConfiguration(filepath.Join(ConfigPath, JETBRAINS_RUN_FILE), target)
+		if err != nil {
+			return target, err
+		}
+	}
+
+	composeFile, found := templates.FindComposeFile(ConfigPath)
+	if !found {
+		return target, errors.New("no compose file found")
+	}
+
+	// NOTE: docker compose merges the files of COMPOSE_FILE in order
+	return target, os.Setenv("COMPOSE_FILE", strings.Join([]string{composeFile, DEBUG_COMPOSE_FILE}, string(os.PathListSeparator)))
+}
+
+// Compose file exposing the debugger of the lambda service
+func debugComposeOverride(target DebugTarget) ([]byte, error) {
+	service := &templates.ComposeService{
+		Ports:       []string{fmt.Sprintf("%d:%d", target.Port, target.Port)},
+		Environment: target.Environment,
+	}
+
+	if target.Dockerfile != "" {
+		service.Build = &templates.ComposeBuild{Context: ".", Dockerfile: target.Dockerfile}
+		// NOTE: delve traces the function with ptrace
+		service.CapAdd = []string{"SYS_PTRACE"}
+		service.SecurityOpt = []string{"seccomp:unconfined"}
+	}
+
+	compose := &templates.ComposeFile{Services: map[string]*templates.ComposeService{COMPOSE_SERVICE: service}}
+	return compose.Marshal()
+}
+
+// Adds the attach configuration to launch.json, replacing the one of a previous run
+func writeVSCodeLaunch(path string, target DebugTarget) error {
+	launch := map[string]any{"version": "0.2.0"}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(data, &launch)
+		if err != nil {
+			return fmt.Errorf("could not parse the existing file: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	configurations, _ := launch["configurations"].([]any)
+	configurations = slices.DeleteFunc(configurations, func(configuration any) bool {
+		named, ok := configuration.(map[string]any)
+		return ok && named["name"] == DEBUG_CONFIGURATION_NAME
+	})
+
+	configuration := map[string]any{"name": DEBUG_CONFIGURATION_NAME}
+	for key, value := range target.VSCode {
+		configuration[key] = value
+	}
+	launch["configurations"] = append(configurations, configuration)
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// NOTE: keep skipFiles patterns such as <node_internals> readable
+	encoder := json.NewEncoder(file)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(launch)
+}
+
+func writeJetBrainsRunConfiguration(path string, target DebugTarget) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf("<component name=\"ProjectRunConfigurationManager\">\n  %s\n</component>\n", target.JetBrains)
+	return os.WriteFile(path, []byte(content), 0644)
+}
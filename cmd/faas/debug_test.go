@@ -0,0 +1,115 @@
+package faas
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestDebugComposeOverride(t *testing.T) {
+	t.Run("should expose the node inspector", func(t *testing.T) {
+		target, _ := LookupDebugTarget("nodejs22.x")
+
+		override, err := debugComposeOverride(target)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		for _, expected := range []string{"9229:9229", "NODE_OPTIONS: --enable-source-maps --inspect=0.0.0.0:9229"} {
+			if !strings.Contains(string(override), expected) {
+				t.Errorf("expected the override to contain %s, but received\n%s", expected, override)
+			}
+		}
+	})
+
+	t.Run("should build go from the debug Dockerfile", func(t *testing.T) {
+		target, _ := LookupDebugTarget("provided.al2023")
+
+		override, err := debugComposeOverride(target)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		for _, expected := range []string{"dockerfile: Dockerfile.debug", "2345:2345", "SYS_PTRACE"} {
+			if !strings.Contains(string(override), expected) {
+				t.Errorf("expected the override to contain %s, but received\n%s", expected, override)
+			}
+		}
+	})
+
+	t.Run("should fail for runtimes without a debugger", func(t *testing.T) {
+		_, err := LookupDebugTarget("ruby3.3")
+		if err == nil {
+			t.Errorf("expected an error for the ruby runtime")
+		}
+	})
+}
+
+func TestInitializeDebugFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	ConfigPath = tmpDir
+	t.Setenv("COMPOSE_FILE", "")
+
+	os.WriteFile(filepath.Join(tmpDir, "docker-compose.yaml"), []byte("services: {}"), 0644)
+	os.MkdirAll(filepath.Join(tmpDir, ".vscode"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, VSCODE_LAUNCH_FILE), []byte(`{
+  "version": "0.2.0",
+  "configurations": [
+    {"name": "tests", "type": "go", "request": "launch"},
+    {"name": "jeeves: attach to FaaS", "type": "node", "request": "attach"}
+  ]
+}`), 0644)
+
+	t.Run("should write the debug files of the runtime", func(t *testing.T) {
+		_, err := initializeDebugFiles(viper.New(), "provided.al2023", "bootstrap", "x86_64")
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		dockerFile, err := readFile(tmpDir, "Dockerfile.debug")
+		if err != nil {
+			t.Errorf("expected no errors reading Dockerfile.debug, but received \"%s\"", err.Error())
+			return
+		}
+		if !strings.Contains(dockerFile, "FROM --platform=linux/amd64 amazon/aws-lambda-provided:al2023") || !strings.Contains(dockerFile, "--listen=:2345") {
+			t.Errorf("unexpected Dockerfile.debug\n%s", dockerFile)
+		}
+
+		runConfiguration, err := readFile(tmpDir, JETBRAINS_RUN_FILE)
+		if err != nil || !strings.Contains(runConfiguration, `type="GoRemoteDebugConfigurationType"`) {
+			t.Errorf("expected a Go Remote run configuration, but received \"%s\"", runConfiguration)
+		}
+
+		if composeFile := os.Getenv("COMPOSE_FILE"); !strings.HasSuffix(composeFile, DEBUG_COMPOSE_FILE) {
+			t.Errorf("expected COMPOSE_FILE to include %s, but received \"%s\"", DEBUG_COMPOSE_FILE, composeFile)
+		}
+	})
+
+	t.Run("should keep the other launch configurations", func(t *testing.T) {
+		data, _ := os.ReadFile(filepath.Join(tmpDir, VSCODE_LAUNCH_FILE))
+
+		var launch struct {
+			Configurations []map[string]any `json:"configurations"`
+		}
+		err := json.Unmarshal(data, &launch)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if len(launch.Configurations) != 2 || launch.Configurations[0]["name"] != "tests" {
+			t.Errorf("expected the tests configuration to be kept, but received %v", launch.Configurations)
+			return
+		}
+		if attach := launch.Configurations[1]; attach["type"] != "go" || attach["mode"] != "remote" {
+			t.Errorf("expected the attach configuration to be replaced, but received %v", attach)
+		}
+	})
+}
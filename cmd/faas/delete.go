@@ -2,16 +2,14 @@ package faas
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/obscurelyme/jeeves/config"
-	"github.com/obscurelyme/jeeves/types"
+	"github.com/obscurelyme/jeeves/utils/repository"
 	"github.com/spf13/cobra"
 )
 
@@ -19,13 +17,15 @@ var resourceName string
 var deleteFaasCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Deletes an existing FaaS resource",
-	Long:  "Opens a prompt to delete an FaaS resource and its corresponding IAM roles",
+	Long:  "Opens a prompt to delete an FaaS resource, its triggers, its corresponding IAM roles and the ECR repository of its images",
 	RunE:  deleteFassCmdHandler,
 }
 
 func init() {
 	deleteFaasCmd.PersistentFlags().StringVar(&profile, "profile", "default", "AWS Profile to work with")
 	deleteFaasCmd.PersistentFlags().StringVar(&resourceName, "resource-name", "", "Name of the FaaS resource to delete. (required)")
+	deleteFaasCmd.PersistentFlags().StringVar(&repositoryProvider, "repository", "", "Repository provider, overrides .jeeves.yaml: github, gitlab, lambda or none")
+	addForceFlag(deleteFaasCmd)
 }
 
 func deleteFassCmdHandler(cmd *cobra.Command, args []string) error {
@@ -41,6 +41,17 @@ func deleteFassCmdHandler(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	err = requireManaged(cfg, resourceName)
+	if err != nil {
+		return err
+	}
+
+	// NOTE: schedules, S3 notifications and event source mappings outlive the function
+	err = RemoveTriggers(cfg, resourceName)
+	if err != nil {
+		return err
+	}
+
 	// Detach policies
 	err = DetachFaaSPolicies(cfg)
 	if err != nil {
@@ -53,6 +64,15 @@ func deleteFassCmdHandler(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Delete the ECR repository created when deploying an image
+	deleted, err := DeleteImageRepository(cfg, resourceName)
+	if err != nil {
+		return err
+	}
+	if deleted {
+		fmt.Printf("ECR repository %s deleted!\n", ImageRepositoryName(resourceName))
+	}
+
 	// Delete the IAM role associated with the FaaS resource
 	err = DeleteFaaSResourceRole(cfg)
 
@@ -66,32 +86,22 @@ func deleteFassCmdHandler(cmd *cobra.Command, args []string) error {
 }
 
 func DeleteFaaSRepo(cfg aws.Config, name string) error {
-	lambdaClient := lambda.NewFromConfig(cfg)
-	functionName := "delete-lambda-repository"
-	payload, err := json.Marshal(&types.DeleteRepositoryPayload{
-		RepositoryOwner: "obscurelyme",
-		RepositoryName:  fmt.Sprintf("%s.lambda", name),
-	})
-
+	provider, err := newRepositoryProvider(cfg)
 	if err != nil {
-		return nil
+		return err
 	}
 
-	output, err := lambdaClient.Invoke(context.TODO(), &lambda.InvokeInput{
-		FunctionName: &functionName,
-		Payload:      payload,
-	})
+	if _, ok := provider.(*repository.NoneProvider); ok {
+		return nil
+	}
 
+	err = provider.Delete(RepositoryName(name))
 	if err != nil {
 		return err
 	}
 
-	if output.StatusCode == http.StatusOK {
-		fmt.Printf("Github repository for %s deleted!\n", resourceName)
-		return nil
-	}
-
-	return fmt.Errorf("lambda failed with status code: %d", output.StatusCode)
+	fmt.Printf("Repository for %s deleted!\n", name)
+	return nil
 }
 
 func DeleteFaaSResource(cfg aws.Config) error {
@@ -116,15 +126,32 @@ func DeleteFaaSResourceRole(cfg aws.Config) error {
 	return err
 }
 
+// Detaches every managed policy from the role, IE: the basic execution role and
+// the policies attached for triggers, so the role may be deleted.
 func DetachFaaSPolicies(cfg aws.Config) error {
 	iamClient := iam.NewFromConfig(cfg)
 
 	roleName := fmt.Sprintf("%s-IamRole", resourceName)
 
-	_, err := iamClient.DetachRolePolicy(context.TODO(), &iam.DetachRolePolicyInput{
-		PolicyArn: &BASIC_LAMBDA_POLICY_ARN,
-		RoleName:  &roleName,
+	paginator := iam.NewListAttachedRolePoliciesPaginator(iamClient, &iam.ListAttachedRolePoliciesInput{
+		RoleName: &roleName,
 	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return err
+		}
+
+		for _, policy := range page.AttachedPolicies {
+			_, err := iamClient.DetachRolePolicy(context.TODO(), &iam.DetachRolePolicyInput{
+				PolicyArn: policy.PolicyArn,
+				RoleName:  &roleName,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
 
-	return err
+	return nil
 }
@@ -0,0 +1,361 @@
+package faas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/obscurelyme/jeeves/config"
+	"github.com/obscurelyme/jeeves/types"
+	"github.com/obscurelyme/jeeves/utils/archive"
+	"github.com/obscurelyme/jeeves/utils/java"
+	pythonUtils "github.com/obscurelyme/jeeves/utils/python"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var deployFaasCmd = &cobra.Command{
+	Use:     "deploy [NAME]",
+	Aliases: []string{"update"},
+	Short:   "Deploys the code of a FaaS resource",
+	Long: `Packages the build output of the FaaS resource in the current directory,
+uploads it as the new code of $LATEST for the selected --arch and applies the layers
+and triggers declared in faas.yaml. Layers may be given by name, resolving to their
+latest version, or by ARN.
+When function.env is set the variables of its env file are pushed, resolving
+ssm: and secretsmanager: references.
+With --package-type image the image is built for the function's architecture from a
+Dockerfile rendered without the debug tooling of faas start, and pushed to an ECR
+repository named after the function, creating the repository and the function when
+needed. Only the newest --keep-images images are kept in the repository, besides the
+images of published versions.
+NAME defaults to function.name of faas.yaml.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: deployFaasCmdHandler,
+}
+
+var deployPackageType string
+var deployKeepImages int
+var deployArchitecture string
+
+func init() {
+	deployFaasCmd.PersistentFlags().StringVar(&profile, "profile", "default", "AWS Profile to work with")
+	deployFaasCmd.PersistentFlags().StringVar(&deployPackageType, "package-type", "", "Zip or Image, defaults to function.packageType of faas.yaml or Zip")
+	deployFaasCmd.PersistentFlags().StringVar(&deployArchitecture, "arch", "", "Architecture of the function, arm64 or x86_64, defaults to function.architecture of faas.yaml")
+	deployFaasCmd.PersistentFlags().IntVar(&deployKeepImages, "keep-images", DEFAULT_IMAGE_RETENTION, "Number of images kept in the ECR repository when deploying an image")
+}
+
+func deployFaasCmdHandler(cmd *cobra.Command, args []string) error {
+	faasConfig, err := ReadLambdaConfig()
+	if err != nil {
+		return err
+	}
+
+	name := faasConfig.GetString("function.name")
+	if len(args) > 0 {
+		name = args[0]
+	}
+	if name == "" {
+		return errors.New("no function name given, pass NAME or set function.name in faas.yaml")
+	}
+
+	triggers, err := ReadTriggers(faasConfig)
+	if err != nil {
+		return err
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	packageType, err := resolvePackageType(deployPackageType, faasConfig.GetString("function.packageType"))
+	if err != nil {
+		return err
+	}
+
+	build, err := types.ParseBuildMode(faasConfig.GetString("function.build"))
+	if err != nil {
+		return err
+	}
+
+	runtime := faasConfig.GetString("function.runtime")
+	architecture, err := resolveArchitecture(deployArchitecture, faasConfig.GetString("function.architecture"), runtime)
+	if err != nil {
+		return err
+	}
+
+	cmd.SilenceUsage = true
+	warnRuntimeDeprecation(runtime)
+
+	// NOTE: container builds of images never copy what was built on the host
+	if packageType == lambdaTypes.PackageTypeZip || build == types.BUILD_HOST {
+		nativeArchitecture := architecture
+		if nativeArchitecture == "" {
+			nativeArchitecture, err = functionArchitecture(cfg, name)
+			if err != nil {
+				return err
+			}
+		}
+		if strings.HasPrefix(runtime, "python") {
+			err = VendorPythonDependencies(runtime, nativeArchitecture)
+			if err != nil {
+				return err
+			}
+		}
+		warnNativeMismatches(runtime, nativeArchitecture)
+	}
+
+	if packageType == lambdaTypes.PackageTypeImage {
+		imageUri, err := DeployFaaSImage(cfg, &DeployImageInput{
+			Name:         name,
+			Runtime:      runtime,
+			Handler:      faasConfig.GetString("function.handler"),
+			Architecture: architecture,
+			Retention:    deployKeepImages,
+			Config:       faasConfig,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Deployed image %s\n", imageUri)
+	} else {
+		code, err := PackageFunction(runtime)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Deploying %s (%d KB)...\n", name, len(code)/1024)
+		err = DeployFaaSResource(cfg, name, code, architecture)
+		if err != nil {
+			return err
+		}
+	}
+
+	if faasConfig.IsSet("function.env") {
+		options, err := ReadEnvironmentOptions(faasConfig)
+		if err != nil {
+			return err
+		}
+
+		count, err := PushEnvironment(cfg, name, options)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Pushed %d variables from %s\n", count, options.File)
+	}
+
+	// NOTE: only touch the layers when faas.yaml declares them, an empty list removes all layers
+	if faasConfig.IsSet("function.layers") {
+		layers, err := ResolveLayers(cfg, faasConfig.GetStringSlice("function.layers"))
+		if err != nil {
+			return err
+		}
+
+		err = UpdateFaaSLayers(cfg, name, layers)
+		if err != nil {
+			return err
+		}
+		for _, layer := range layers {
+			fmt.Printf("Attached layer %s\n", layer)
+		}
+	}
+
+	for _, trigger := range triggers {
+		id, err := ApplyTrigger(cfg, name, trigger)
+		if err != nil {
+			return fmt.Errorf("could not apply %s trigger: %w", trigger.Type, err)
+		}
+		fmt.Printf("Applied trigger %s\n", id)
+	}
+
+	fmt.Printf("FaaS resource, %s, was successfully deployed!\n", name)
+	return nil
+}
+
+// Reads and validates the triggers declared under triggers of faas.yaml
+func ReadTriggers(faasConfig *viper.Viper) ([]*Trigger, error) {
+	triggers := []*Trigger{}
+
+	err := faasConfig.UnmarshalKey("triggers", &triggers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid triggers in %s: %w", FAAS_CONFIG_FILE, err)
+	}
+
+	for i, trigger := range triggers {
+		err = trigger.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid trigger %d in %s: %w", i, FAAS_CONFIG_FILE, err)
+		}
+	}
+
+	return triggers, nil
+}
+
+// Resolves the package type of the flag, falling back to faas.yaml and then Zip. IE: image -> Image
+func resolvePackageType(flag string, configured string) (lambdaTypes.PackageType, error) {
+	value := flag
+	if value == "" {
+		value = configured
+	}
+	if value == "" {
+		return lambdaTypes.PackageTypeZip, nil
+	}
+
+	for _, packageType := range lambdaTypes.PackageTypeZip.Values() {
+		if strings.EqualFold(value, string(packageType)) {
+			return packageType, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid package type \"%s\", expected zip or image", value)
+}
+
+// Zips the build output of the runtime in the layout Lambda expects,
+// mirroring what the generated Dockerfiles copy into the image.
+func PackageFunction(runtime string) ([]byte, error) {
+	entries, err := deploymentEntries(runtime)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if _, err := os.Stat(entry.Source); err != nil {
+			return nil, fmt.Errorf("%s is missing, build the function before deploying", entry.Source)
+		}
+	}
+
+	return archive.Zip(entries)
+}
+
+func deploymentEntries(runtime string) ([]archive.Entry, error) {
+	path := func(name string) string {
+		return filepath.Join(ConfigPath, name)
+	}
+
+	switch {
+	case strings.HasPrefix(runtime, "nodejs"):
+		return []archive.Entry{
+			{Source: path("dist"), Target: "dist"},
+			{Source: path("node_modules"), Target: "node_modules"},
+			{Source: path("package.json")},
+		}, nil
+	case strings.HasPrefix(runtime, "provided"):
+		return []archive.Entry{
+			{Source: path("bootstrap")},
+		}, nil
+	case strings.HasPrefix(runtime, "java"):
+		project, err := java.DetectProject(ConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		// NOTE: Lambda adds every jar of lib to the classpath
+		if project.Shadow {
+			jar, err := project.ShadowJar()
+			if err != nil {
+				return nil, err
+			}
+			return []archive.Entry{
+				{Source: jar, Target: "lib/" + filepath.Base(jar)},
+			}, nil
+		}
+		return []archive.Entry{
+			{Source: path(project.ClassesPath())},
+			{Source: path(project.DependencyPath()), Target: "lib"},
+		}, nil
+	case strings.HasPrefix(runtime, "python"):
+		return []archive.Entry{
+			{Source: path(pythonUtils.PACKAGES_DIR)},
+			{Source: path("src")},
+		}, nil
+	case strings.HasPrefix(runtime, "ruby"):
+		// NOTE: gems are installed with "bundle config set --local path vendor/bundle"
+		return []archive.Entry{
+			{Source: path("handler.rb")},
+			{Source: path("Gemfile")},
+			{Source: path("vendor"), Target: "vendor"},
+			{Source: path(".bundle"), Target: ".bundle"},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("deploying the \"%s\" runtime is not supported", runtime)
+}
+
+// Installs the dependencies of the python project into pythonUtils.PACKAGES_DIR,
+// from its lock or requirements file or else its venv, so neither an active venv
+// nor the host's site-packages are needed. Only wheels of the runtime's python and the
+// architecture are installed, warns when the venv runs another python than the runtime.
+func VendorPythonDependencies(runtime string, architecture lambdaTypes.Architecture) error {
+	platform, err := pythonUtils.NewLambdaPlatform(runtime, architecture)
+	if err != nil {
+		return err
+	}
+
+	project, err := pythonUtils.DetectProject(ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	if project.VenvVersion != "" && project.VenvVersion != runtime {
+		fmt.Fprintf(os.Stderr, "WARNING: %s runs %s but the function runs on %s\n", project.Venv, project.VenvVersion, runtime)
+	}
+	if project.Manager == pythonUtils.MANAGER_NONE {
+		fmt.Fprintf(os.Stderr, "WARNING: no requirements.txt, pyproject.toml, lock file or venv found, the function is deployed without dependencies\n")
+	}
+
+	fmt.Printf("Vendoring %s dependencies for %s %s into %s\n", project.Manager, runtime, platform.Machine, pythonUtils.PACKAGES_DIR)
+	report, err := project.Vendor(filepath.Join(ConfigPath, pythonUtils.PACKAGES_DIR), platform)
+	if err != nil {
+		return err
+	}
+
+	if report.Container {
+		fmt.Fprintf(os.Stderr, "WARNING: some dependencies have no %s wheel, installed them within %s\n", strings.Join(platform.Tags(), " or "), platform.Image)
+	}
+	if len(report.Native) > 0 {
+		fmt.Printf("Packages with native code for %s: %s\n", platform.Machine, strings.Join(report.Native, ", "))
+	}
+
+	return nil
+}
+
+// Uploads the zip as the new code of $LATEST and waits for the update to finish.
+// The architecture of the function is only changed when one is given.
+func DeployFaaSResource(cfg aws.Config, name string, code []byte, architecture lambdaTypes.Architecture) error {
+	client := lambda.NewFromConfig(cfg)
+
+	_, err := client.GetFunctionConfiguration(context.TODO(), &lambda.GetFunctionConfigurationInput{
+		FunctionName: &name,
+	})
+	if isResourceNotFound(err) {
+		return fmt.Errorf("FaaS resource %s does not exist, create it first with \"jeeves faas create\"", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	input := &lambda.UpdateFunctionCodeInput{
+		FunctionName: &name,
+		ZipFile:      code,
+	}
+	if architecture != "" {
+		input.Architectures = []lambdaTypes.Architecture{architecture}
+	}
+
+	_, err = client.UpdateFunctionCode(context.TODO(), input)
+	if err != nil {
+		return err
+	}
+
+	waiter := lambda.NewFunctionUpdatedV2Waiter(client)
+	return waiter.Wait(context.TODO(), &lambda.GetFunctionInput{
+		FunctionName: &name,
+	}, DEFAULT_WAIT_DURATION)
+}
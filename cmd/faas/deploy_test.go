@@ -0,0 +1,122 @@
+package faas
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	pythonUtils "github.com/obscurelyme/jeeves/utils/python"
+)
+
+var runPythonCommand = pythonUtils.RunCommand
+
+func TestPackageFunction(t *testing.T) {
+	tmpDir := t.TempDir()
+	ConfigPath = tmpDir
+
+	t.Run("should zip the java classes and dependencies", func(t *testing.T) {
+		os.MkdirAll(filepath.Join(tmpDir, "target/classes/com/example"), 0755)
+		os.MkdirAll(filepath.Join(tmpDir, "target/dependency"), 0755)
+		os.WriteFile(filepath.Join(tmpDir, "target/classes/com/example/Handler.class"), []byte("class"), 0644)
+		os.WriteFile(filepath.Join(tmpDir, "target/dependency/lib.jar"), []byte("jar"), 0644)
+
+		data, err := PackageFunction("java21")
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		expected := []string{"com/example/Handler.class", "lib/lib.jar"}
+		if names := zipNames(t, data); !slices.Equal(names, expected) {
+			t.Errorf("expected %v, but received %v", expected, names)
+		}
+	})
+
+	t.Run("should zip the shadow jar of gradle projects", func(t *testing.T) {
+		gradleDir := t.TempDir()
+		ConfigPath = gradleDir
+		defer func() { ConfigPath = tmpDir }()
+
+		os.WriteFile(filepath.Join(gradleDir, "build.gradle.kts"), []byte("plugins {\n    id(\"com.gradleup.shadow\") version \"8.3.5\"\n}"), 0644)
+		os.MkdirAll(filepath.Join(gradleDir, "build/libs"), 0755)
+		os.WriteFile(filepath.Join(gradleDir, "build/libs/handler-all.jar"), []byte("jar"), 0644)
+
+		data, err := PackageFunction("java21")
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		expected := []string{"lib/handler-all.jar"}
+		if names := zipNames(t, data); !slices.Equal(names, expected) {
+			t.Errorf("expected %v, but received %v", expected, names)
+		}
+	})
+
+	t.Run("should vendor the python dependencies without an active venv", func(t *testing.T) {
+		t.Setenv(pythonUtils.VIRTUAL_ENV, "")
+		os.MkdirAll(filepath.Join(tmpDir, "src"), 0755)
+		os.WriteFile(filepath.Join(tmpDir, "src/main.py"), []byte(""), 0644)
+		os.WriteFile(filepath.Join(tmpDir, "requirements.txt"), []byte("requests"), 0644)
+
+		pythonUtils.RunCommand = func(dir string, name string, args ...string) ([]byte, error) {
+			target := args[slices.Index(args, "--target")+1]
+			os.MkdirAll(filepath.Join(target, "requests"), 0755)
+			return nil, os.WriteFile(filepath.Join(target, "requests/__init__.py"), []byte(""), 0644)
+		}
+		defer func() { pythonUtils.RunCommand = runPythonCommand }()
+
+		err := VendorPythonDependencies("python3.12", "arm64")
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		data, err := PackageFunction("python3.12")
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		expected := []string{"main.py", "requests/__init__.py"}
+		if names := zipNames(t, data); !slices.Equal(names, expected) {
+			t.Errorf("expected %v, but received %v", expected, names)
+		}
+	})
+
+	t.Run("should fail when the build output is missing", func(t *testing.T) {
+		_, err := PackageFunction("provided.al2023")
+		if err == nil {
+			t.Errorf("expected an error for a missing bootstrap")
+		}
+	})
+}
+
+func TestResolvePackageType(t *testing.T) {
+	t.Run("should prefer the flag over faas.yaml", func(t *testing.T) {
+		packageType, err := resolvePackageType("image", "Zip")
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if packageType != lambdaTypes.PackageTypeImage {
+			t.Errorf("expected Image, but received %s", packageType)
+		}
+	})
+
+	t.Run("should default to zip", func(t *testing.T) {
+		packageType, _ := resolvePackageType("", "")
+		if packageType != lambdaTypes.PackageTypeZip {
+			t.Errorf("expected Zip, but received %s", packageType)
+		}
+	})
+
+	t.Run("should reject unknown package types", func(t *testing.T) {
+		_, err := resolvePackageType("", "tarball")
+		if err == nil {
+			t.Errorf("expected an error for an unknown package type")
+		}
+	})
+}
@@ -0,0 +1,429 @@
+package faas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/obscurelyme/jeeves/config"
+	"github.com/spf13/cobra"
+)
+
+// Value printed in place of environment variable values unless --show-secrets is set
+const REDACTED_VALUE string = "********"
+
+var showSecrets bool
+var describeJson bool
+var describeFaasCmd = &cobra.Command{
+	Use:   "describe NAME",
+	Short: "Describes a FaaS resource",
+	Long:  "Prints a single report of a FaaS resource's configuration, permissions, aliases, versions, triggers and execution role",
+	Args:  cobra.ExactArgs(1),
+	RunE:  describeFaasCmdHandler,
+}
+
+func init() {
+	describeFaasCmd.PersistentFlags().StringVar(&profile, "profile", "default", "AWS Profile to work with")
+	describeFaasCmd.PersistentFlags().BoolVar(&showSecrets, "show-secrets", false, "Print environment variable values instead of redacting them")
+	describeFaasCmd.PersistentFlags().BoolVar(&describeJson, "json", false, "Print the report as JSON")
+}
+
+type FunctionAliasReport struct {
+	Name            string             `json:"name"`
+	FunctionVersion string             `json:"functionVersion"`
+	Description     string             `json:"description,omitempty"`
+	RoutingWeights  map[string]float64 `json:"routingWeights,omitempty"`
+}
+
+type FunctionVersionReport struct {
+	Version      string `json:"version"`
+	Description  string `json:"description,omitempty"`
+	LastModified string `json:"lastModified"`
+	CodeSha256   string `json:"codeSha256"`
+}
+
+type EventSourceMappingReport struct {
+	UUID           string `json:"uuid"`
+	EventSourceArn string `json:"eventSourceArn"`
+	State          string `json:"state"`
+	BatchSize      int32  `json:"batchSize"`
+}
+
+type FunctionUrlReport struct {
+	Url      string `json:"url"`
+	AuthType string `json:"authType"`
+}
+
+type ExecutionRoleReport struct {
+	Arn              string   `json:"arn"`
+	Name             string   `json:"name"`
+	AttachedPolicies []string `json:"attachedPolicies"`
+	InlinePolicies   []string `json:"inlinePolicies"`
+}
+
+// Aggregated view of everything jeeves knows about a deployed FaaS resource
+type FunctionReport struct {
+	Name                string                     `json:"name"`
+	Arn                 string                     `json:"arn"`
+	Description         string                     `json:"description,omitempty"`
+	Runtime             string                     `json:"runtime,omitempty"`
+	Handler             string                     `json:"handler,omitempty"`
+	PackageType         string                     `json:"packageType"`
+	Architectures       []string                   `json:"architectures"`
+	MemorySize          int32                      `json:"memorySize"`
+	Timeout             int32                      `json:"timeout"`
+	CodeSize            int64                      `json:"codeSize"`
+	CodeSha256          string                     `json:"codeSha256"`
+	CodeLocation        string                     `json:"codeLocation,omitempty"`
+	ImageUri            string                     `json:"imageUri,omitempty"`
+	Version             string                     `json:"version"`
+	State               string                     `json:"state"`
+	LastModified        string                     `json:"lastModified"`
+	ReservedConcurrency *int32                     `json:"reservedConcurrency,omitempty"`
+	Layers              []string                   `json:"layers,omitempty"`
+	Environment         map[string]string          `json:"environment,omitempty"`
+	Tags                map[string]string          `json:"tags,omitempty"`
+	Policy              json.RawMessage            `json:"policy,omitempty"`
+	Aliases             []FunctionAliasReport      `json:"aliases"`
+	Versions            []FunctionVersionReport    `json:"versions"`
+	EventSourceMappings []EventSourceMappingReport `json:"eventSourceMappings"`
+	Url                 *FunctionUrlReport         `json:"url,omitempty"`
+	Role                ExecutionRoleReport        `json:"role"`
+}
+
+func describeFaasCmdHandler(cmd *cobra.Command, args []string) error {
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	report, err := DescribeFaaSResource(cfg, args[0], showSecrets)
+	if err != nil {
+		return err
+	}
+
+	if describeJson {
+		return writeJson(os.Stdout, report)
+	}
+
+	writeFunctionReport(os.Stdout, report)
+	return nil
+}
+
+// Collects the configuration, resource policy, aliases, versions, event sources,
+// function url and execution role of a lambda function into a single report.
+func DescribeFaaSResource(cfg aws.Config, name string, showSecrets bool) (*FunctionReport, error) {
+	ctx := context.TODO()
+	lambdaClient := lambda.NewFromConfig(cfg)
+	iamClient := iam.NewFromConfig(cfg)
+
+	function, err := lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: &name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	configuration, err := lambdaClient.GetFunctionConfiguration(ctx, &lambda.GetFunctionConfigurationInput{
+		FunctionName: &name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := newFunctionReport(configuration, showSecrets)
+	report.Tags = function.Tags
+	if function.Code != nil {
+		report.CodeLocation = aws.ToString(function.Code.Location)
+		report.ImageUri = aws.ToString(function.Code.ImageUri)
+	}
+	if function.Concurrency != nil {
+		report.ReservedConcurrency = function.Concurrency.ReservedConcurrentExecutions
+	}
+
+	policy, err := lambdaClient.GetPolicy(ctx, &lambda.GetPolicyInput{
+		FunctionName: &name,
+	})
+	if err != nil && !isResourceNotFound(err) {
+		return nil, err
+	}
+	if policy != nil && policy.Policy != nil {
+		report.Policy = json.RawMessage(*policy.Policy)
+	}
+
+	aliases := lambda.NewListAliasesPaginator(lambdaClient, &lambda.ListAliasesInput{
+		FunctionName: &name,
+	})
+	for aliases.HasMorePages() {
+		page, err := aliases.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, alias := range page.Aliases {
+			aliasReport := FunctionAliasReport{
+				Name:            aws.ToString(alias.Name),
+				FunctionVersion: aws.ToString(alias.FunctionVersion),
+				Description:     aws.ToString(alias.Description),
+			}
+			if alias.RoutingConfig != nil {
+				aliasReport.RoutingWeights = alias.RoutingConfig.AdditionalVersionWeights
+			}
+			report.Aliases = append(report.Aliases, aliasReport)
+		}
+	}
+
+	versions := lambda.NewListVersionsByFunctionPaginator(lambdaClient, &lambda.ListVersionsByFunctionInput{
+		FunctionName: &name,
+	})
+	for versions.HasMorePages() {
+		page, err := versions.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range page.Versions {
+			report.Versions = append(report.Versions, FunctionVersionReport{
+				Version:      aws.ToString(version.Version),
+				Description:  aws.ToString(version.Description),
+				LastModified: aws.ToString(version.LastModified),
+				CodeSha256:   aws.ToString(version.CodeSha256),
+			})
+		}
+	}
+
+	mappings := lambda.NewListEventSourceMappingsPaginator(lambdaClient, &lambda.ListEventSourceMappingsInput{
+		FunctionName: &name,
+	})
+	for mappings.HasMorePages() {
+		page, err := mappings.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, mapping := range page.EventSourceMappings {
+			report.EventSourceMappings = append(report.EventSourceMappings, EventSourceMappingReport{
+				UUID:           aws.ToString(mapping.UUID),
+				EventSourceArn: aws.ToString(mapping.EventSourceArn),
+				State:          aws.ToString(mapping.State),
+				BatchSize:      aws.ToInt32(mapping.BatchSize),
+			})
+		}
+	}
+
+	url, err := lambdaClient.GetFunctionUrlConfig(ctx, &lambda.GetFunctionUrlConfigInput{
+		FunctionName: &name,
+	})
+	if err != nil && !isResourceNotFound(err) {
+		return nil, err
+	}
+	if url != nil {
+		report.Url = &FunctionUrlReport{
+			Url:      aws.ToString(url.FunctionUrl),
+			AuthType: string(url.AuthType),
+		}
+	}
+
+	report.Role.Name = roleNameFromArn(report.Role.Arn)
+	attached := iam.NewListAttachedRolePoliciesPaginator(iamClient, &iam.ListAttachedRolePoliciesInput{
+		RoleName: &report.Role.Name,
+	})
+	for attached.HasMorePages() {
+		page, err := attached.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range page.AttachedPolicies {
+			report.Role.AttachedPolicies = append(report.Role.AttachedPolicies, aws.ToString(policy.PolicyArn))
+		}
+	}
+
+	inline := iam.NewListRolePoliciesPaginator(iamClient, &iam.ListRolePoliciesInput{
+		RoleName: &report.Role.Name,
+	})
+	for inline.HasMorePages() {
+		page, err := inline.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		report.Role.InlinePolicies = append(report.Role.InlinePolicies, page.PolicyNames...)
+	}
+
+	return report, nil
+}
+
+func newFunctionReport(configuration *lambda.GetFunctionConfigurationOutput, showSecrets bool) *FunctionReport {
+	report := &FunctionReport{
+		Name:         aws.ToString(configuration.FunctionName),
+		Arn:          aws.ToString(configuration.FunctionArn),
+		Description:  aws.ToString(configuration.Description),
+		Runtime:      string(configuration.Runtime),
+		Handler:      aws.ToString(configuration.Handler),
+		PackageType:  string(configuration.PackageType),
+		MemorySize:   aws.ToInt32(configuration.MemorySize),
+		Timeout:      aws.ToInt32(configuration.Timeout),
+		CodeSize:     configuration.CodeSize,
+		CodeSha256:   aws.ToString(configuration.CodeSha256),
+		Version:      aws.ToString(configuration.Version),
+		State:        string(configuration.State),
+		LastModified: aws.ToString(configuration.LastModified),
+		Role: ExecutionRoleReport{
+			Arn: aws.ToString(configuration.Role),
+		},
+	}
+
+	for _, arch := range configuration.Architectures {
+		report.Architectures = append(report.Architectures, string(arch))
+	}
+
+	for _, layer := range configuration.Layers {
+		report.Layers = append(report.Layers, aws.ToString(layer.Arn))
+	}
+
+	if configuration.Environment != nil {
+		report.Environment = redactEnvironment(configuration.Environment.Variables, showSecrets)
+	}
+
+	return report
+}
+
+// Returns a copy of the environment variables with every value replaced by
+// REDACTED_VALUE, unless showSecrets is true.
+func redactEnvironment(variables map[string]string, showSecrets bool) map[string]string {
+	if variables == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(variables))
+	for key, value := range variables {
+		if showSecrets {
+			redacted[key] = value
+		} else {
+			redacted[key] = REDACTED_VALUE
+		}
+	}
+
+	return redacted
+}
+
+// Extracts the role name from an IAM role ARN, dropping any role path.
+//
+// IE: arn:aws:iam::123456789012:role/service-role/my-role -> my-role
+func roleNameFromArn(arn string) string {
+	index := strings.LastIndex(arn, "/")
+	if index == -1 {
+		return arn
+	}
+
+	return arn[index+1:]
+}
+
+func isResourceNotFound(err error) bool {
+	var notFound *lambdaTypes.ResourceNotFoundException
+	return errors.As(err, &notFound)
+}
+
+func writeJson(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func writeFunctionReport(w io.Writer, report *FunctionReport) {
+	fmt.Fprintf(w, "Function: %s\n", report.Name)
+	fmt.Fprintf(w, "ARN: %s\n", report.Arn)
+	if report.Description != "" {
+		fmt.Fprintf(w, "Description: %s\n", report.Description)
+	}
+	fmt.Fprintf(w, "State: %s\n", report.State)
+	fmt.Fprintf(w, "Package Type: %s\n", report.PackageType)
+	if report.Runtime != "" {
+		fmt.Fprintf(w, "Runtime: %s\n", report.Runtime)
+	}
+	if report.Handler != "" {
+		fmt.Fprintf(w, "Handler: %s\n", report.Handler)
+	}
+	if report.ImageUri != "" {
+		fmt.Fprintf(w, "Image: %s\n", report.ImageUri)
+	}
+	fmt.Fprintf(w, "Architectures: %s\n", strings.Join(report.Architectures, ", "))
+	fmt.Fprintf(w, "Memory: %d MB\n", report.MemorySize)
+	fmt.Fprintf(w, "Timeout: %d seconds\n", report.Timeout)
+	fmt.Fprintf(w, "Code Size: %d bytes\n", report.CodeSize)
+	fmt.Fprintf(w, "Code SHA256: %s\n", report.CodeSha256)
+	fmt.Fprintf(w, "Last Modified: %s\n", report.LastModified)
+	if report.ReservedConcurrency != nil {
+		fmt.Fprintf(w, "Reserved Concurrency: %d\n", *report.ReservedConcurrency)
+	}
+
+	fmt.Fprintln(w, "---\nEnvironment:")
+	for _, key := range sortedKeys(report.Environment) {
+		fmt.Fprintf(w, "  %s=%s\n", key, report.Environment[key])
+	}
+
+	fmt.Fprintln(w, "---\nTags:")
+	for _, key := range sortedKeys(report.Tags) {
+		fmt.Fprintf(w, "  %s=%s\n", key, report.Tags[key])
+	}
+
+	fmt.Fprintln(w, "---\nLayers:")
+	for _, layer := range report.Layers {
+		fmt.Fprintf(w, "  %s\n", layer)
+	}
+
+	fmt.Fprintln(w, "---\nVersions:")
+	for _, version := range report.Versions {
+		fmt.Fprintf(w, "  %s (%s) %s\n", version.Version, version.LastModified, version.Description)
+	}
+
+	fmt.Fprintln(w, "---\nAliases:")
+	for _, alias := range report.Aliases {
+		fmt.Fprintf(w, "  %s -> %s", alias.Name, alias.FunctionVersion)
+		for _, version := range sortedKeys(alias.RoutingWeights) {
+			fmt.Fprintf(w, " (%s: %.0f%%)", version, alias.RoutingWeights[version]*100)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "---\nEvent Sources:")
+	for _, mapping := range report.EventSourceMappings {
+		fmt.Fprintf(w, "  %s [%s] batch size %d (%s)\n", mapping.EventSourceArn, mapping.State, mapping.BatchSize, mapping.UUID)
+	}
+
+	fmt.Fprintln(w, "---\nFunction URL:")
+	if report.Url != nil {
+		fmt.Fprintf(w, "  %s (%s)\n", report.Url.Url, report.Url.AuthType)
+	}
+
+	fmt.Fprintln(w, "---\nResource Policy:")
+	if report.Policy != nil {
+		fmt.Fprintf(w, "  %s\n", string(report.Policy))
+	}
+
+	fmt.Fprintln(w, "---\nExecution Role:")
+	fmt.Fprintf(w, "  %s\n", report.Role.Arn)
+	for _, policy := range report.Role.AttachedPolicies {
+		fmt.Fprintf(w, "  attached: %s\n", policy)
+	}
+	for _, policy := range report.Role.InlinePolicies {
+		fmt.Fprintf(w, "  inline: %s\n", policy)
+	}
+	fmt.Fprintln(w, "---")
+}
@@ -0,0 +1,45 @@
+package faas
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+func TestDescribeFaaS(t *testing.T) {
+	configuration := &lambda.GetFunctionConfigurationOutput{
+		FunctionName: aws.String("my-function"),
+		Role:         aws.String("arn:aws:iam::123456789012:role/service-role/my-function-IamRole"),
+		Environment: &lambdaTypes.EnvironmentResponse{
+			Variables: map[string]string{
+				"DB_PASSWORD": "hunter2",
+			},
+		},
+	}
+
+	t.Run("should redact environment variable values by default", func(t *testing.T) {
+		report := newFunctionReport(configuration, false)
+
+		if report.Environment["DB_PASSWORD"] != REDACTED_VALUE {
+			t.Errorf("expected value to be redacted, but received \"%s\"", report.Environment["DB_PASSWORD"])
+		}
+	})
+
+	t.Run("should print environment variable values with --show-secrets", func(t *testing.T) {
+		report := newFunctionReport(configuration, true)
+
+		if report.Environment["DB_PASSWORD"] != "hunter2" {
+			t.Errorf("expected value to be \"hunter2\", but received \"%s\"", report.Environment["DB_PASSWORD"])
+		}
+	})
+
+	t.Run("should strip the path from the execution role name", func(t *testing.T) {
+		name := roleNameFromArn(*configuration.Role)
+
+		if name != "my-function-IamRole" {
+			t.Errorf("expected role name to be \"my-function-IamRole\", but received \"%s\"", name)
+		}
+	})
+}
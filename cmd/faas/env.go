@@ -0,0 +1,601 @@
+package faas
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/obscurelyme/jeeves/config"
+	jeevesEnv "github.com/obscurelyme/jeeves/env"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const SSM_REFERENCE_PREFIX string = "ssm:"
+const SECRETS_MANAGER_REFERENCE_PREFIX string = "secretsmanager:"
+
+// Variables set by the Lambda runtime which may not be overridden, the local
+// .env also holds the AWS credentials written by "faas start".
+var RESERVED_VARIABLES = []string{
+	"_HANDLER",
+	"_X_AMZN_TRACE_ID",
+	"AWS_ACCESS_KEY",
+	"AWS_ACCESS_KEY_ID",
+	"AWS_DEFAULT_REGION",
+	"AWS_EXECUTION_ENV",
+	"AWS_LAMBDA_FUNCTION_MEMORY_SIZE",
+	"AWS_LAMBDA_FUNCTION_NAME",
+	"AWS_LAMBDA_FUNCTION_VERSION",
+	"AWS_LAMBDA_INITIALIZATION_TYPE",
+	"AWS_LAMBDA_LOG_GROUP_NAME",
+	"AWS_LAMBDA_LOG_STREAM_NAME",
+	"AWS_LAMBDA_RUNTIME_API",
+	"AWS_REGION",
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_SESSION_TOKEN",
+	"LAMBDA_RUNTIME_DIR",
+	"LAMBDA_TASK_ROOT",
+}
+
+// Settings of the env subcommands, read from function.env of faas.yaml when
+// the matching flag is not given
+type EnvironmentOptions struct {
+	// Local env file to sync with
+	File string `mapstructure:"file"`
+	// Customer managed KMS key used to encrypt the variables at rest
+	KmsKey string `mapstructure:"kmsKey"`
+	// Variables additionally encrypted in transit with the KMS key
+	Encrypt []string `mapstructure:"encrypt"`
+}
+
+// Looks up the value of a reference, IE: kind "ssm" and name "/prod/db/password"
+type ReferenceLookup func(kind string, name string) (string, error)
+
+type EnvironmentChange struct {
+	Key string
+	// "+" only set locally, "-" only set on the function, "~" set on both with different values
+	Change string
+}
+
+var envOptions = EnvironmentOptions{}
+var envFaasCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Sync the environment variables of a FaaS resource",
+	Long: `Syncs the environment variables of a FaaS resource with a local env file.
+
+Values may reference SSM parameters or Secrets Manager secrets, which are
+resolved whenever the variables are pushed or deployed, IE:
+
+  DB_PASSWORD=ssm:/prod/db/password
+  API_KEY=secretsmanager:prod/api-key
+  API_SECRET=secretsmanager:prod/api#secret
+
+Reserved variables such as the AWS credentials are never synced.`,
+}
+
+var pullEnvCmd = &cobra.Command{
+	Use:   "pull NAME",
+	Short: "Writes the function's variables to the env file",
+	Args:  cobra.ExactArgs(1),
+	RunE:  pullEnvCmdHandler,
+}
+
+var pushEnvCmd = &cobra.Command{
+	Use:   "push NAME",
+	Short: "Replaces the function's variables with the env file",
+	Args:  cobra.ExactArgs(1),
+	RunE:  pushEnvCmdHandler,
+}
+
+var setEnvCmd = &cobra.Command{
+	Use:   "set NAME KEY=VALUE...",
+	Short: "Sets variables of the function",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  setEnvCmdHandler,
+}
+
+var unsetEnvCmd = &cobra.Command{
+	Use:   "unset NAME KEY...",
+	Short: "Removes variables from the function",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  unsetEnvCmdHandler,
+}
+
+var diffEnvCmd = &cobra.Command{
+	Use:   "diff NAME",
+	Short: "Compares the env file with the function's variables",
+	Args:  cobra.ExactArgs(1),
+	RunE:  diffEnvCmdHandler,
+}
+
+func init() {
+	envFaasCmd.PersistentFlags().StringVar(&profile, "profile", "default", "AWS Profile to work with")
+	envFaasCmd.PersistentFlags().StringVar(&envOptions.File, "file", ".env", "Local env file")
+
+	for _, cmd := range []*cobra.Command{pushEnvCmd, setEnvCmd, unsetEnvCmd} {
+		cmd.PersistentFlags().StringVar(&envOptions.KmsKey, "kms-key", "", "Customer managed KMS key to encrypt the variables with")
+		cmd.PersistentFlags().StringSliceVar(&envOptions.Encrypt, "encrypt", []string{}, "Variables to also encrypt in transit with the KMS key")
+	}
+
+	addForceFlag(pushEnvCmd)
+	addForceFlag(unsetEnvCmd)
+
+	envFaasCmd.AddCommand(pullEnvCmd)
+	envFaasCmd.AddCommand(pushEnvCmd)
+	envFaasCmd.AddCommand(setEnvCmd)
+	envFaasCmd.AddCommand(unsetEnvCmd)
+	envFaasCmd.AddCommand(diffEnvCmd)
+}
+
+// Fills in the options the user did not pass as flags from faas.yaml
+func environmentOptions(cmd *cobra.Command) (*EnvironmentOptions, error) {
+	options := envOptions
+
+	faasConfig, err := ReadOptionalLambdaConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	fileOptions, err := ReadEnvironmentOptions(faasConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := cmd.Flags()
+	if !flags.Changed("file") && fileOptions.File != "" {
+		options.File = fileOptions.File
+	}
+	if !flags.Changed("kms-key") {
+		options.KmsKey = fileOptions.KmsKey
+	}
+	if !flags.Changed("encrypt") {
+		options.Encrypt = fileOptions.Encrypt
+	}
+
+	return &options, nil
+}
+
+// Reads the env settings under function.env of faas.yaml
+func ReadEnvironmentOptions(faasConfig *viper.Viper) (*EnvironmentOptions, error) {
+	options := &EnvironmentOptions{File: ".env"}
+
+	err := faasConfig.UnmarshalKey("function.env", options)
+	if err != nil {
+		return nil, fmt.Errorf("invalid env settings in %s: %w", FAAS_CONFIG_FILE, err)
+	}
+
+	return options, nil
+}
+
+func pullEnvCmdHandler(cmd *cobra.Command, args []string) error {
+	options, err := environmentOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	remote, err := GetFaaSEnvironment(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	local, err := jeevesEnv.ReadVariables(options.File)
+	if err != nil {
+		return err
+	}
+
+	err = jeevesEnv.WriteVariables(options.File, mergePulledVariables(local, remote, options.Encrypt))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Pulled %d variables of %s into %s\n", len(remote), args[0], options.File)
+	return nil
+}
+
+func pushEnvCmdHandler(cmd *cobra.Command, args []string) error {
+	options, err := environmentOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	err = requireManaged(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	cmd.SilenceUsage = true
+	count, err := PushEnvironment(cfg, args[0], options)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Pushed %d variables from %s to %s\n", count, options.File, args[0])
+	return nil
+}
+
+func setEnvCmdHandler(cmd *cobra.Command, args []string) error {
+	updates := map[string]string{}
+	for _, arg := range args[1:] {
+		key, value, found := strings.Cut(arg, "=")
+		if !found || key == "" {
+			return fmt.Errorf("invalid variable \"%s\", expected KEY=VALUE", arg)
+		}
+		if isReservedVariable(key) {
+			return fmt.Errorf("%s is reserved by the Lambda runtime", key)
+		}
+		updates[key] = value
+	}
+
+	options, err := environmentOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	cmd.SilenceUsage = true
+	variables, err := GetFaaSEnvironment(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	for key, value := range updates {
+		variables[key] = value
+	}
+
+	// NOTE: the other values are already encrypted, only encrypt the ones being set
+	options.Encrypt = slices.DeleteFunc(slices.Clone(options.Encrypt), func(key string) bool {
+		_, ok := updates[key]
+		return !ok
+	})
+
+	err = UpdateFaaSEnvironment(cfg, args[0], variables, options)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Set %s on %s\n", strings.Join(sortedKeys(updates), ", "), args[0])
+	return nil
+}
+
+func unsetEnvCmdHandler(cmd *cobra.Command, args []string) error {
+	options, err := environmentOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	err = requireManaged(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	cmd.SilenceUsage = true
+	variables, err := GetFaaSEnvironment(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, key := range args[1:] {
+		delete(variables, key)
+	}
+
+	// NOTE: the remaining values are already resolved and encrypted
+	err = UpdateFaaSEnvironment(cfg, args[0], variables, &EnvironmentOptions{KmsKey: options.KmsKey})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Unset %s on %s\n", strings.Join(args[1:], ", "), args[0])
+	return nil
+}
+
+func diffEnvCmdHandler(cmd *cobra.Command, args []string) error {
+	options, err := environmentOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	cmd.SilenceUsage = true
+	remote, err := GetFaaSEnvironment(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	local, err := readLocalVariables(options.File)
+	if err != nil {
+		return err
+	}
+
+	local, err = resolveReferences(local, awsReferenceLookup(cfg))
+	if err != nil {
+		return err
+	}
+
+	changes := diffEnvironment(local, remote, options.Encrypt)
+	if len(changes) == 0 {
+		fmt.Printf("%s matches %s\n", options.File, args[0])
+		return nil
+	}
+
+	for _, change := range changes {
+		fmt.Printf("%s %s\n", change.Change, change.Key)
+	}
+	return nil
+}
+
+// Replaces the function's variables with the variables of the env file,
+// returning how many were pushed.
+func PushEnvironment(cfg aws.Config, name string, options *EnvironmentOptions) (int, error) {
+	variables, err := readLocalVariables(options.File)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(variables), UpdateFaaSEnvironment(cfg, name, variables, options)
+}
+
+// Reads the variables of the function's $LATEST
+func GetFaaSEnvironment(cfg aws.Config, name string) (map[string]string, error) {
+	client := lambda.NewFromConfig(cfg)
+
+	output, err := client.GetFunctionConfiguration(context.TODO(), &lambda.GetFunctionConfigurationInput{
+		FunctionName: &name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if output.Environment == nil || output.Environment.Variables == nil {
+		return map[string]string{}, nil
+	}
+
+	return output.Environment.Variables, nil
+}
+
+// Resolves references, encrypts the variables listed in the options and
+// replaces the function's variables, waiting for the update to finish.
+func UpdateFaaSEnvironment(cfg aws.Config, name string, variables map[string]string, options *EnvironmentOptions) error {
+	client := lambda.NewFromConfig(cfg)
+
+	resolved, err := resolveReferences(variables, awsReferenceLookup(cfg))
+	if err != nil {
+		return err
+	}
+
+	if len(options.Encrypt) > 0 {
+		if options.KmsKey == "" {
+			return fmt.Errorf("encrypting %s requires a KMS key, --kms-key [KEY]", strings.Join(options.Encrypt, ", "))
+		}
+
+		resolved, err = encryptVariables(cfg, name, options.KmsKey, resolved, options.Encrypt)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = client.UpdateFunctionConfiguration(context.TODO(), &lambda.UpdateFunctionConfigurationInput{
+		FunctionName: &name,
+		Environment:  &lambdaTypes.Environment{Variables: resolved},
+		KMSKeyArn:    optionalString(options.KmsKey),
+	})
+	if err != nil {
+		return err
+	}
+
+	waiter := lambda.NewFunctionUpdatedV2Waiter(client)
+	return waiter.Wait(context.TODO(), &lambda.GetFunctionInput{
+		FunctionName: &name,
+	}, DEFAULT_WAIT_DURATION)
+}
+
+// Encrypts the values the same way the Lambda console's encryption helpers do,
+// so they may be decrypted in the function with kms:Decrypt.
+func encryptVariables(cfg aws.Config, name string, keyId string, variables map[string]string, keys []string) (map[string]string, error) {
+	client := kms.NewFromConfig(cfg)
+	encrypted := map[string]string{}
+
+	for key, value := range variables {
+		if !slices.Contains(keys, key) {
+			encrypted[key] = value
+			continue
+		}
+
+		output, err := client.Encrypt(context.TODO(), &kms.EncryptInput{
+			KeyId:             &keyId,
+			Plaintext:         []byte(value),
+			EncryptionContext: map[string]string{"LambdaFunctionName": name},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not encrypt %s: %w", key, err)
+		}
+
+		encrypted[key] = base64.StdEncoding.EncodeToString(output.CiphertextBlob)
+	}
+
+	return encrypted, nil
+}
+
+func readLocalVariables(file string) (map[string]string, error) {
+	variables, err := jeevesEnv.ReadVariables(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for key := range variables {
+		if isReservedVariable(key) {
+			delete(variables, key)
+		}
+	}
+
+	return variables, nil
+}
+
+func awsReferenceLookup(cfg aws.Config) ReferenceLookup {
+	return func(kind string, name string) (string, error) {
+		switch kind {
+		case "ssm":
+			client := ssm.NewFromConfig(cfg)
+			output, err := client.GetParameter(context.TODO(), &ssm.GetParameterInput{
+				Name:           &name,
+				WithDecryption: aws.Bool(true),
+			})
+			if err != nil {
+				return "", err
+			}
+			return aws.ToString(output.Parameter.Value), nil
+		case "secretsmanager":
+			client := secretsmanager.NewFromConfig(cfg)
+			output, err := client.GetSecretValue(context.TODO(), &secretsmanager.GetSecretValueInput{
+				SecretId: &name,
+			})
+			if err != nil {
+				return "", err
+			}
+			return aws.ToString(output.SecretString), nil
+		}
+
+		return "", fmt.Errorf("unknown reference kind \"%s\"", kind)
+	}
+}
+
+// Splits a reference into its kind and name, IE: ssm:/prod/db -> ssm, /prod/db
+func parseReference(value string) (string, string, bool) {
+	if name, found := strings.CutPrefix(value, SSM_REFERENCE_PREFIX); found && name != "" {
+		return "ssm", name, true
+	}
+	if name, found := strings.CutPrefix(value, SECRETS_MANAGER_REFERENCE_PREFIX); found && name != "" {
+		return "secretsmanager", name, true
+	}
+
+	return "", "", false
+}
+
+// Replaces ssm: and secretsmanager: references with their values. A secret
+// holding JSON may be narrowed to a single field, IE: secretsmanager:prod/api#key
+func resolveReferences(variables map[string]string, lookup ReferenceLookup) (map[string]string, error) {
+	resolved := map[string]string{}
+
+	for key, value := range variables {
+		kind, name, ok := parseReference(value)
+		if !ok {
+			resolved[key] = value
+			continue
+		}
+
+		field := ""
+		if kind == "secretsmanager" {
+			name, field, _ = strings.Cut(name, "#")
+		}
+
+		secret, err := lookup(kind, name)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve %s of %s: %w", value, key, err)
+		}
+
+		if field != "" {
+			fields := map[string]any{}
+			err = json.Unmarshal([]byte(secret), &fields)
+			if err != nil {
+				return nil, fmt.Errorf("secret %s of %s is not a JSON object", name, key)
+			}
+
+			fieldValue, ok := fields[field]
+			if !ok {
+				return nil, fmt.Errorf("secret %s of %s has no field %s", name, key, field)
+			}
+			secret = fmt.Sprint(fieldValue)
+		}
+
+		resolved[key] = secret
+	}
+
+	return resolved, nil
+}
+
+// Merges pulled variables into the local ones. References and encrypted
+// variables keep their local value, since the function only holds the
+// resolved or encrypted value, and reserved variables are left untouched.
+func mergePulledVariables(local map[string]string, remote map[string]string, encrypted []string) map[string]string {
+	merged := map[string]string{}
+
+	for key, value := range local {
+		if isReservedVariable(key) {
+			merged[key] = value
+		}
+	}
+
+	for key, value := range remote {
+		localValue, ok := local[key]
+		_, _, isReference := parseReference(localValue)
+		if ok && (isReference || slices.Contains(encrypted, key)) {
+			value = localValue
+		}
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// Lists the keys which differ, values are not printed since they are often secrets.
+// Encrypted variables are only compared by their presence.
+func diffEnvironment(local map[string]string, remote map[string]string, encrypted []string) []EnvironmentChange {
+	changes := []EnvironmentChange{}
+
+	for _, key := range sortedKeys(local) {
+		remoteValue, ok := remote[key]
+		if !ok {
+			changes = append(changes, EnvironmentChange{Key: key, Change: "+"})
+		} else if remoteValue != local[key] && !slices.Contains(encrypted, key) {
+			changes = append(changes, EnvironmentChange{Key: key, Change: "~"})
+		}
+	}
+
+	for _, key := range sortedKeys(remote) {
+		if _, ok := local[key]; !ok {
+			changes = append(changes, EnvironmentChange{Key: key, Change: "-"})
+		}
+	}
+
+	return changes
+}
+
+func isReservedVariable(key string) bool {
+	return slices.Contains(RESERVED_VARIABLES, key)
+}
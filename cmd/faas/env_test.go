@@ -0,0 +1,136 @@
+package faas
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func mockLookup(kind string, name string) (string, error) {
+	values := map[string]string{
+		"ssm:/prod/db/password":     "hunter2",
+		"secretsmanager:prod/api":   `{"key": "abc", "secret": "xyz"}`,
+		"secretsmanager:prod/plain": "plain-secret",
+	}
+
+	value, ok := values[kind+":"+name]
+	if !ok {
+		return "", errors.New("not found")
+	}
+
+	return value, nil
+}
+
+func TestResolveReferences(t *testing.T) {
+	t.Run("should resolve ssm and secretsmanager references", func(t *testing.T) {
+		resolved, err := resolveReferences(map[string]string{
+			"DB_PASSWORD": "ssm:/prod/db/password",
+			"API_SECRET":  "secretsmanager:prod/api#secret",
+			"PLAIN":       "secretsmanager:prod/plain",
+			"STAGE":       "prod",
+		}, mockLookup)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		expected := map[string]string{
+			"DB_PASSWORD": "hunter2",
+			"API_SECRET":  "xyz",
+			"PLAIN":       "plain-secret",
+			"STAGE":       "prod",
+		}
+		if !maps.Equal(resolved, expected) {
+			t.Errorf("expected %v, but received %v", expected, resolved)
+		}
+	})
+
+	t.Run("should fail for missing references and fields", func(t *testing.T) {
+		_, err := resolveReferences(map[string]string{"MISSING": "ssm:/missing"}, mockLookup)
+		if err == nil {
+			t.Errorf("expected an error for a missing parameter")
+		}
+
+		_, err = resolveReferences(map[string]string{"FIELD": "secretsmanager:prod/api#missing"}, mockLookup)
+		if err == nil {
+			t.Errorf("expected an error for a missing secret field")
+		}
+	})
+}
+
+func TestMergePulledVariables(t *testing.T) {
+	t.Run("should keep references, encrypted and reserved local values", func(t *testing.T) {
+		local := map[string]string{
+			"AWS_ACCESS_KEY_ID": "local-key",
+			"DB_PASSWORD":       "ssm:/prod/db/password",
+			"TOKEN":             "plain-token",
+			"REMOVED":           "gone",
+		}
+		remote := map[string]string{
+			"DB_PASSWORD": "hunter2",
+			"TOKEN":       "AQICAHh...",
+			"STAGE":       "prod",
+		}
+
+		merged := mergePulledVariables(local, remote, []string{"TOKEN"})
+		expected := map[string]string{
+			"AWS_ACCESS_KEY_ID": "local-key",
+			"DB_PASSWORD":       "ssm:/prod/db/password",
+			"TOKEN":             "plain-token",
+			"STAGE":             "prod",
+		}
+		if !maps.Equal(merged, expected) {
+			t.Errorf("expected %v, but received %v", expected, merged)
+		}
+	})
+}
+
+func TestDiffEnvironment(t *testing.T) {
+	t.Run("should list added, removed and changed keys", func(t *testing.T) {
+		changes := diffEnvironment(
+			map[string]string{"ADDED": "1", "CHANGED": "new", "SAME": "x", "TOKEN": "plain"},
+			map[string]string{"REMOVED": "1", "CHANGED": "old", "SAME": "x", "TOKEN": "cipher"},
+			[]string{"TOKEN"},
+		)
+
+		expected := []EnvironmentChange{
+			{Key: "ADDED", Change: "+"},
+			{Key: "CHANGED", Change: "~"},
+			{Key: "REMOVED", Change: "-"},
+		}
+		if !slices.Equal(changes, expected) {
+			t.Errorf("expected %v, but received %v", expected, changes)
+		}
+	})
+}
+
+func TestReadEnvironmentOptions(t *testing.T) {
+	tmpDir := t.TempDir()
+	ConfigPath = tmpDir
+
+	t.Run("should read the env settings of faas.yaml", func(t *testing.T) {
+		setup(tmpDir, `function:
+  env:
+    file: .env.prod
+    kmsKey: alias/my-key
+    encrypt:
+      - TOKEN`)
+
+		faasConfig, err := ReadLambdaConfig()
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		options, err := ReadEnvironmentOptions(faasConfig)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if options.File != ".env.prod" || options.KmsKey != "alias/my-key" || !slices.Equal(options.Encrypt, []string{"TOKEN"}) {
+			t.Errorf("unexpected options %+v", options)
+		}
+	})
+}
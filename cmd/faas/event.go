@@ -0,0 +1,79 @@
+package faas
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/obscurelyme/jeeves/templates/events"
+	"github.com/spf13/cobra"
+)
+
+var eventOverrides []string
+var eventBody string
+var eventBodyFile string
+var eventBase64 bool
+var eventOutput string
+var eventFaasCmd = &cobra.Command{
+	Use:   "event",
+	Short: "Work with sample FaaS events",
+	Long:  "Generate sample events for common Lambda triggers",
+}
+
+var generateEventCmd = &cobra.Command{
+	Use:   "generate SOURCE",
+	Short: "Generates a sample event",
+	Long: fmt.Sprintf(`Generates a sample event for the given trigger source and prints it to stdout,
+ready to be piped into "jeeves faas invoke".
+
+Sources: %s`, strings.Join(events.Sources(), ", ")),
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: events.Sources(),
+	RunE:      generateEventCmdHandler,
+}
+
+func init() {
+	generateEventCmd.PersistentFlags().StringArrayVar(&eventOverrides, "set", []string{}, "Override a field, IE: --set Records.0.s3.bucket.name=my-bucket")
+	generateEventCmd.PersistentFlags().StringVar(&eventBody, "body", "", "Body of the event, placed where the source expects it")
+	generateEventCmd.PersistentFlags().StringVar(&eventBodyFile, "body-file", "", "File containing the body of the event")
+	generateEventCmd.PersistentFlags().BoolVar(&eventBase64, "base64", false, "Base64 encode the body")
+	generateEventCmd.PersistentFlags().StringVarP(&eventOutput, "output", "o", "", "Write the event to a file instead of stdout")
+	eventFaasCmd.AddCommand(generateEventCmd)
+}
+
+func generateEventCmdHandler(cmd *cobra.Command, args []string) error {
+	input := &events.GenerateInput{
+		Source:    args[0],
+		Overrides: eventOverrides,
+		Base64:    eventBase64,
+	}
+
+	if eventBody != "" && eventBodyFile != "" {
+		return fmt.Errorf("only one of --body or --body-file may be provided")
+	}
+
+	if eventBody != "" {
+		input.Body = &eventBody
+	}
+
+	if eventBodyFile != "" {
+		data, err := os.ReadFile(eventBodyFile)
+		if err != nil {
+			return err
+		}
+		body := string(data)
+		input.Body = &body
+	}
+
+	event, err := events.Generate(input)
+	if err != nil {
+		return err
+	}
+
+	if eventOutput != "" {
+		return os.WriteFile(eventOutput, event, 0644)
+	}
+
+	fmt.Println(string(event))
+	return nil
+}
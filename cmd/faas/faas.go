@@ -10,8 +10,21 @@ var FaasRootCmd = &cobra.Command{
 
 func init() {
 	FaasRootCmd.AddCommand(listFaasCmd)
+	FaasRootCmd.AddCommand(initFaasCmd)
 	FaasRootCmd.AddCommand(createFaasCmd)
 	FaasRootCmd.AddCommand(deleteFaasCmd)
 	FaasRootCmd.AddCommand(startFaasCmd)
-	FaasRootCmd.AddCommand(updateFaasCmd)
+	FaasRootCmd.AddCommand(describeFaasCmd)
+	FaasRootCmd.AddCommand(invokeFaasCmd)
+	FaasRootCmd.AddCommand(eventFaasCmd)
+	FaasRootCmd.AddCommand(logsFaasCmd)
+	FaasRootCmd.AddCommand(publishFaasCmd)
+	FaasRootCmd.AddCommand(aliasFaasCmd)
+	FaasRootCmd.AddCommand(releaseFaasCmd)
+	FaasRootCmd.AddCommand(urlFaasCmd)
+	FaasRootCmd.AddCommand(deployFaasCmd)
+	FaasRootCmd.AddCommand(triggerFaasCmd)
+	FaasRootCmd.AddCommand(layerFaasCmd)
+	FaasRootCmd.AddCommand(envFaasCmd)
+	FaasRootCmd.AddCommand(templatesFaasCmd)
 }
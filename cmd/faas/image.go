@@ -0,0 +1,383 @@
+package faas
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	ecrTypes "github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/obscurelyme/jeeves/types"
+	"github.com/obscurelyme/jeeves/utils/tags"
+	"github.com/spf13/viper"
+)
+
+// Number of images kept in the ECR repository of a FaaS resource, older images are pruned after a deploy
+const DEFAULT_IMAGE_RETENTION int = 10
+
+// Tag format of the images pushed by deploy, IE: 20261019T153000
+const IMAGE_TAG_FORMAT string = "20060102T150405"
+
+// Input of DeployFaaSImage
+type DeployImageInput struct {
+	Name    string
+	Runtime string
+	Handler string
+	// Architecture the image is built for, defaults to the function's architecture
+	Architecture lambdaTypes.Architecture
+	// Number of images to keep in the ECR repository
+	Retention int
+	// faas.yaml of the function, IE: function.build and function.dockerfile.env
+	Config *viper.Viper
+}
+
+// Builds the image of the FaaS resource from a Dockerfile rendered for Lambda, pushes
+// it to the ECR repository of the function and deploys it as the new code of $LATEST.
+// The Dockerfile of faas start is never used, it carries the debug tooling of local runs.
+func DeployFaaSImage(cfg aws.Config, input *DeployImageInput) (string, error) {
+	lambdaClient := lambda.NewFromConfig(cfg)
+
+	configuration, err := lambdaClient.GetFunctionConfiguration(context.TODO(), &lambda.GetFunctionConfigurationInput{
+		FunctionName: &input.Name,
+	})
+	exists := !isResourceNotFound(err)
+	if err != nil && exists {
+		return "", err
+	}
+
+	architecture := input.Architecture
+	if exists && configuration.PackageType != lambdaTypes.PackageTypeImage {
+		return "", fmt.Errorf("FaaS resource %s uses the %s package type which cannot be changed, deploy it without --package-type image", input.Name, configuration.PackageType)
+	}
+	if architecture == "" && exists && len(configuration.Architectures) > 0 {
+		architecture = configuration.Architectures[0]
+	}
+	if architecture == "" {
+		architecture = types.DEFAULT_ARCHITECTURE
+	}
+
+	dockerfile, err := writeDeployDockerfile(input.Config, input.Runtime, input.Handler, architecture)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(dockerfile)
+
+	resourceTags, err := tags.New(cfg, input.Name)
+	if err != nil {
+		return "", err
+	}
+
+	ecrClient := ecr.NewFromConfig(cfg)
+	repositoryUri, err := EnsureImageRepository(ecrClient, ImageRepositoryName(input.Name), resourceTags)
+	if err != nil {
+		return "", err
+	}
+
+	imageUri := fmt.Sprintf("%s:%s", repositoryUri, time.Now().UTC().Format(IMAGE_TAG_FORMAT))
+	fmt.Printf("Building %s for %s...\n", imageUri, architecture)
+	err = runDocker(nil, "build", "--platform", types.DockerPlatform(architecture), "--provenance=false", "-f", dockerfile, "-t", imageUri, ConfigPath)
+	if err != nil {
+		return "", err
+	}
+
+	err = dockerLogin(ecrClient, repositoryUri)
+	if err != nil {
+		return "", err
+	}
+
+	err = runDocker(nil, "push", imageUri)
+	if err != nil {
+		return "", err
+	}
+
+	if exists {
+		_, err = lambdaClient.UpdateFunctionCode(context.TODO(), &lambda.UpdateFunctionCodeInput{
+			FunctionName:  &input.Name,
+			ImageUri:      &imageUri,
+			Architectures: []lambdaTypes.Architecture{architecture},
+		})
+		if err != nil {
+			return "", err
+		}
+
+		waiter := lambda.NewFunctionUpdatedV2Waiter(lambdaClient)
+		err = waiter.Wait(context.TODO(), &lambda.GetFunctionInput{
+			FunctionName: &input.Name,
+		}, DEFAULT_WAIT_DURATION)
+	} else {
+		err = createFaaSImageResource(lambdaClient, input.Name, imageUri, architecture, resourceTags)
+		if err != nil {
+			return "", err
+		}
+
+		waiter := lambda.NewFunctionActiveV2Waiter(lambdaClient)
+		err = waiter.Wait(context.TODO(), &lambda.GetFunctionInput{
+			FunctionName: &input.Name,
+		}, DEFAULT_WAIT_DURATION)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	referenced, err := referencedImageDigests(lambdaClient, input.Name)
+	if err != nil {
+		return "", fmt.Errorf("deployed %s but could not list the images of its versions: %w", imageUri, err)
+	}
+
+	pruned, err := PruneImages(ecrClient, ImageRepositoryName(input.Name), input.Retention, referenced)
+	if err != nil {
+		return "", fmt.Errorf("deployed %s but could not prune old images: %w", imageUri, err)
+	}
+	if pruned > 0 {
+		fmt.Printf("Pruned %d old images\n", pruned)
+	}
+
+	return imageUri, nil
+}
+
+// ECR repository names must be lowercase
+func ImageRepositoryName(functionName string) string {
+	return strings.ToLower(functionName)
+}
+
+// Returns the uri of the ECR repository, creating it when it does not exist yet
+func EnsureImageRepository(client *ecr.Client, name string, resourceTags map[string]string) (string, error) {
+	output, err := client.DescribeRepositories(context.TODO(), &ecr.DescribeRepositoriesInput{
+		RepositoryNames: []string{name},
+	})
+
+	var notFound *ecrTypes.RepositoryNotFoundException
+	if errors.As(err, &notFound) {
+		created, err := client.CreateRepository(context.TODO(), &ecr.CreateRepositoryInput{
+			RepositoryName: &name,
+			Tags:           tags.ToECR(resourceTags),
+		})
+		if err != nil {
+			return "", err
+		}
+		fmt.Printf("Created ECR repository %s\n", name)
+		return aws.ToString(created.Repository.RepositoryUri), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return aws.ToString(output.Repositories[0].RepositoryUri), nil
+}
+
+// Deletes all but the newest images of the repository, returning how many were deleted.
+// Referenced digests, IE: of published versions, are never deleted.
+func PruneImages(client *ecr.Client, name string, retention int, referenced []string) (int, error) {
+	images := []ecrTypes.ImageDetail{}
+
+	paginator := ecr.NewDescribeImagesPaginator(client, &ecr.DescribeImagesInput{
+		RepositoryName: &name,
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return 0, err
+		}
+		images = append(images, output.ImageDetails...)
+	}
+
+	expired := expiredImages(images, retention, referenced)
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	// NOTE: BatchDeleteImage accepts at most 100 images per call
+	for batch := range slices.Chunk(expired, 100) {
+		_, err := client.BatchDeleteImage(context.TODO(), &ecr.BatchDeleteImageInput{
+			RepositoryName: &name,
+			ImageIds:       batch,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(expired), nil
+}
+
+// Images beyond the newest retention images, by push date, except the referenced digests
+func expiredImages(images []ecrTypes.ImageDetail, retention int, referenced []string) []ecrTypes.ImageIdentifier {
+	if retention < 1 {
+		retention = 1
+	}
+
+	sorted := slices.Clone(images)
+	slices.SortFunc(sorted, func(a, b ecrTypes.ImageDetail) int {
+		return aws.ToTime(b.ImagePushedAt).Compare(aws.ToTime(a.ImagePushedAt))
+	})
+
+	expired := []ecrTypes.ImageIdentifier{}
+	for i := retention; i < len(sorted); i++ {
+		if slices.Contains(referenced, aws.ToString(sorted[i].ImageDigest)) {
+			continue
+		}
+		expired = append(expired, ecrTypes.ImageIdentifier{ImageDigest: sorted[i].ImageDigest})
+	}
+
+	return expired
+}
+
+// Digests of the images $LATEST and the published versions of the function run. Aliases
+// and their weighted routing always point at published versions, so rollbacks and canary
+// releases keep their images.
+func referencedImageDigests(client *lambda.Client, name string) ([]string, error) {
+	digests := []string{}
+
+	paginator := lambda.NewListVersionsByFunctionPaginator(client, &lambda.ListVersionsByFunctionInput{
+		FunctionName: &name,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, version := range page.Versions {
+			function, err := client.GetFunction(context.TODO(), &lambda.GetFunctionInput{
+				FunctionName: &name,
+				Qualifier:    version.Version,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if function.Code == nil {
+				continue
+			}
+
+			// IE: 123456789012.dkr.ecr.us-east-1.amazonaws.com/my-function@sha256:...
+			if _, digest, found := strings.Cut(aws.ToString(function.Code.ResolvedImageUri), "@"); found {
+				digests = append(digests, digest)
+			}
+		}
+	}
+
+	return digests, nil
+}
+
+// Deletes the ECR repository of the function with all of its images, nothing is
+// deleted when the function was never deployed as an image
+func DeleteImageRepository(cfg aws.Config, functionName string) (bool, error) {
+	client := ecr.NewFromConfig(cfg)
+
+	_, err := client.DeleteRepository(context.TODO(), &ecr.DeleteRepositoryInput{
+		RepositoryName: aws.String(ImageRepositoryName(functionName)),
+		Force:          true,
+	})
+
+	var notFound *ecrTypes.RepositoryNotFoundException
+	if errors.As(err, &notFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func createFaaSImageResource(client *lambda.Client, name string, imageUri string, architecture lambdaTypes.Architecture, resourceTags map[string]string) error {
+	input := types.CreateFaaSResourceInput{FunctionName: name, Tags: resourceTags}
+
+	roleArn, _, err := CreateLambdaRole(&input)
+	if err != nil {
+		return err
+	}
+
+	var defaultTimeout int32 = 30
+	fmt.Println("This may take some time please be patient\nIAM roles and Policies can take up to 30 seconds\nbefore taking effect...")
+
+	// NOTE: retry until the new role may be assumed by Lambda, for up to 1 minute
+	for retry := 12; ; retry-- {
+		_, err = client.CreateFunction(context.TODO(), &lambda.CreateFunctionInput{
+			FunctionName:  &name,
+			PackageType:   lambdaTypes.PackageTypeImage,
+			Code:          &lambdaTypes.FunctionCode{ImageUri: &imageUri},
+			Role:          &roleArn,
+			Architectures: []lambdaTypes.Architecture{architecture},
+			Timeout:       &defaultTimeout,
+			Tags:          resourceTags,
+		})
+
+		var invalid *lambdaTypes.InvalidParameterValueException
+		if err == nil || !errors.As(err, &invalid) || retry == 0 {
+			return err
+		}
+
+		fmt.Println("...")
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// Writes the Dockerfile of the deployed image to a temporary file and returns its path
+func writeDeployDockerfile(faasConfig *viper.Viper, runtime string, handler string, architecture lambdaTypes.Architecture) (string, error) {
+	dockerFile, err := newDockerfile(faasConfig, runtime, handler, architecture, true)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp("", "Dockerfile.deploy-*")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(dockerFile.Content())
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+// Authenticates docker against the registry of the repository
+func dockerLogin(client *ecr.Client, repositoryUri string) error {
+	output, err := client.GetAuthorizationToken(context.TODO(), &ecr.GetAuthorizationTokenInput{})
+	if err != nil {
+		return err
+	}
+	if len(output.AuthorizationData) == 0 {
+		return errors.New("ECR returned no authorization data")
+	}
+
+	// NOTE: the token is base64 of "AWS:<password>"
+	token, err := base64.StdEncoding.DecodeString(aws.ToString(output.AuthorizationData[0].AuthorizationToken))
+	if err != nil {
+		return err
+	}
+	username, password, found := strings.Cut(string(token), ":")
+	if !found {
+		return errors.New("malformed ECR authorization token")
+	}
+
+	registry, _, _ := strings.Cut(repositoryUri, "/")
+	return runDocker(strings.NewReader(password), "login", "--username", username, "--password-stdin", registry)
+}
+
+func runDocker(stdin *strings.Reader, args ...string) error {
+	dockerCmd := exec.Command("docker", args...)
+
+	if stdin != nil {
+		dockerCmd.Stdin = stdin
+	}
+	dockerCmd.Stdout = os.Stdout
+	dockerCmd.Stderr = os.Stderr
+
+	err := dockerCmd.Run()
+	if err != nil {
+		return fmt.Errorf("docker %s failed: %w", args[0], err)
+	}
+
+	return nil
+}
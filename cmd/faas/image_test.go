@@ -0,0 +1,54 @@
+package faas
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ecrTypes "github.com/aws/aws-sdk-go-v2/service/ecr/types"
+)
+
+func TestExpiredImages(t *testing.T) {
+	pushed := func(digest string, day int) ecrTypes.ImageDetail {
+		return ecrTypes.ImageDetail{
+			ImageDigest:   aws.String(digest),
+			ImagePushedAt: aws.Time(time.Date(2026, 10, day, 0, 0, 0, 0, time.UTC)),
+		}
+	}
+	images := []ecrTypes.ImageDetail{pushed("b", 2), pushed("d", 4), pushed("a", 1), pushed("c", 3)}
+
+	t.Run("should expire all but the newest images", func(t *testing.T) {
+		digests := []string{}
+		for _, id := range expiredImages(images, 2, nil) {
+			digests = append(digests, aws.ToString(id.ImageDigest))
+		}
+
+		if expected := []string{"b", "a"}; !slices.Equal(digests, expected) {
+			t.Errorf("expected %v, but received %v", expected, digests)
+		}
+	})
+
+	t.Run("should always keep the newest image", func(t *testing.T) {
+		if expired := expiredImages(images, 0, nil); len(expired) != 3 {
+			t.Errorf("expected 3 expired images, but received %d", len(expired))
+		}
+	})
+
+	t.Run("should expire nothing within the retention", func(t *testing.T) {
+		if expired := expiredImages(images, 10, nil); len(expired) != 0 {
+			t.Errorf("expected no expired images, but received %d", len(expired))
+		}
+	})
+
+	t.Run("should keep the images of published versions", func(t *testing.T) {
+		digests := []string{}
+		for _, id := range expiredImages(images, 2, []string{"a"}) {
+			digests = append(digests, aws.ToString(id.ImageDigest))
+		}
+
+		if expected := []string{"b"}; !slices.Equal(digests, expected) {
+			t.Errorf("expected %v, but received %v", expected, digests)
+		}
+	})
+}
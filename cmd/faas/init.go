@@ -0,0 +1,131 @@
+package faas
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+
+	"github.com/obscurelyme/jeeves/templates/projects"
+	"github.com/obscurelyme/jeeves/types"
+	"github.com/spf13/cobra"
+)
+
+var initRuntime string
+var initDir string
+var initNoGit bool
+var initArchitecture string
+var initFaasCmd = &cobra.Command{
+	Use:   "init [NAME]",
+	Short: "Scaffolds a new FaaS project locally",
+	Long: `Generates a working FaaS project from the templates built into jeeves,
+no network access is required. Opens a prompt for anything not given, IE:
+
+  jeeves faas init my-function --runtime nodejs`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: initFaasCmdHandler,
+}
+
+func init() {
+	initFaasCmd.PersistentFlags().StringVar(&initRuntime, "runtime", "", "Language or runtime of the project, IE: nodejs, golang, java, python or nodejs22.x")
+	initFaasCmd.PersistentFlags().StringVar(&initDir, "dir", "", "Directory to create the project in, defaults to NAME")
+	initFaasCmd.PersistentFlags().StringVar(&initArchitecture, "arch", string(types.DEFAULT_ARCHITECTURE), "Architecture of the function, arm64 or x86_64")
+	initFaasCmd.PersistentFlags().BoolVar(&initNoGit, "no-git", false, "Do not initialize a git repository")
+}
+
+func initFaasCmdHandler(cmd *cobra.Command, args []string) error {
+	var name string
+	var err error
+	if len(args) > 0 {
+		name = args[0]
+		err = ValidateFunctionName(name)
+	} else {
+		name, err = promptInput()
+	}
+	if err != nil {
+		return err
+	}
+
+	var runtime types.LambdaRuntime
+	if initRuntime != "" {
+		runtime, err = findRuntimeOption(initRuntime)
+	} else {
+		runtime, err = promptLambdaRuntimeSelect()
+	}
+	if err != nil {
+		return err
+	}
+	warnRuntimeDeprecation(string(runtime.AWSRuntime))
+
+	architecture, err := resolveArchitecture(initArchitecture, "", string(runtime.AWSRuntime))
+	if err != nil {
+		return err
+	}
+
+	dir := initDir
+	if dir == "" {
+		dir = name
+	}
+
+	cmd.SilenceUsage = true
+	data := projects.NewProjectData(name, &runtime)
+	if architecture != "" {
+		data.Architecture = string(architecture)
+	}
+
+	files, err := projects.Write(dir, data)
+	if err != nil {
+		return err
+	}
+
+	slices.Sort(files)
+	for _, file := range files {
+		fmt.Printf("  created %s\n", filepath.Join(dir, file))
+	}
+
+	if !initNoGit {
+		err = gitInit(dir)
+		if err != nil {
+			fmt.Printf("Skipped git init: %s\n", err.Error())
+		}
+	}
+
+	fmt.Printf("FaaS project %s (%s) is ready in %s\n", name, runtime.AWSRuntime, dir)
+	return nil
+}
+
+// Finds the runtime option by its language or AWS runtime, IE: nodejs or nodejs22.x.
+// An AWS runtime may be a deprecated one, which only warns.
+func findRuntimeOption(value string) (types.LambdaRuntime, error) {
+	for _, option := range types.RuntimeSelectionOptions {
+		if string(option.Language) == value {
+			return option, nil
+		}
+	}
+
+	if info, err := types.LookupRuntime(value); err == nil {
+		return info.Option(), nil
+	}
+
+	valid := []string{}
+	for _, option := range types.RuntimeSelectionOptions {
+		valid = append(valid, string(option.Language))
+	}
+
+	return types.LambdaRuntime{}, fmt.Errorf("unknown runtime \"%s\", expected one of %v", value, valid)
+}
+
+func gitInit(dir string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return errors.New("git is not installed")
+	}
+
+	gitCmd := exec.Command("git", "init", "--quiet", dir)
+	output, err := gitCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %s", err.Error(), output)
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package faas
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/obscurelyme/jeeves/config"
+	"github.com/spf13/cobra"
+)
+
+var invokeData string
+var invokePayloadFile string
+var invokeQualifier string
+var invokeType string
+var invokeClientContext string
+var invokeLocal bool
+var invokeLocalPort int
+var invokeLocalWait time.Duration
+var invokeFaasCmd = &cobra.Command{
+	Use:   "invoke [NAME]",
+	Short: "Invokes a deployed FaaS resource",
+	Long: `Invokes a deployed FaaS resource and prints its response.
+The payload is read from --data, from --payload (use "-" for stdin) or from piped stdin.
+Exits with a non-zero code when the function returns an error.
+
+With --local the event is posted to the runtime interface emulator started by
+"jeeves faas start" instead, --payload may then also be a directory of .json fixtures.`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: invokeFaasCmdHandler,
+}
+
+func init() {
+	invokeFaasCmd.PersistentFlags().StringVar(&profile, "profile", "default", "AWS Profile to work with")
+	invokeFaasCmd.PersistentFlags().StringVarP(&invokeData, "data", "d", "", "Inline JSON payload")
+	invokeFaasCmd.PersistentFlags().StringVarP(&invokePayloadFile, "payload", "p", "", "File containing the JSON payload, \"-\" reads from stdin")
+	invokeFaasCmd.PersistentFlags().StringVarP(&invokeQualifier, "qualifier", "q", "", "Version or alias to invoke")
+	invokeFaasCmd.PersistentFlags().StringVarP(&invokeType, "invocation-type", "t", string(lambdaTypes.InvocationTypeRequestResponse), "RequestResponse, Event or DryRun")
+	invokeFaasCmd.PersistentFlags().StringVar(&invokeClientContext, "client-context", "", "JSON client context passed to the function")
+	invokeFaasCmd.PersistentFlags().BoolVar(&invokeLocal, "local", false, "Invoke the function running locally via \"jeeves faas start\"")
+	invokeFaasCmd.PersistentFlags().IntVar(&invokeLocalPort, "port", LOCAL_INVOKE_PORT, "Port of the local runtime interface emulator")
+	invokeFaasCmd.PersistentFlags().DurationVar(&invokeLocalWait, "wait", 30*time.Second, "How long to wait for the local container to be ready")
+}
+
+func invokeFaasCmdHandler(cmd *cobra.Command, args []string) error {
+	if invokeLocal {
+		cmd.SilenceUsage = true
+		return invokeLocalHandler(invokeLocalPort, invokeLocalWait)
+	}
+
+	if len(args) == 0 {
+		return errors.New("function name is required unless --local is set")
+	}
+
+	payload, err := ReadPayload(invokeData, invokePayloadFile, os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	invocationType, err := parseInvocationType(invokeType)
+	if err != nil {
+		return err
+	}
+
+	clientContext, err := encodeClientContext(invokeClientContext)
+	if err != nil {
+		return err
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	cmd.SilenceUsage = true
+	return InvokeFaaSResource(cfg, &lambda.InvokeInput{
+		FunctionName:   &args[0],
+		Payload:        payload,
+		InvocationType: invocationType,
+		ClientContext:  clientContext,
+		Qualifier:      optionalString(invokeQualifier),
+		LogType:        lambdaTypes.LogTypeTail,
+	})
+}
+
+// Invokes the lambda function, printing the decoded log tail to stderr and the
+// response to stdout. An error is returned when the function itself errored.
+func InvokeFaaSResource(cfg aws.Config, input *lambda.InvokeInput) error {
+	client := lambda.NewFromConfig(cfg)
+
+	if input.InvocationType != lambdaTypes.InvocationTypeRequestResponse {
+		input.LogType = lambdaTypes.LogTypeNone
+	}
+
+	output, err := client.Invoke(context.TODO(), input)
+	if err != nil {
+		return err
+	}
+
+	logs, err := decodeLogResult(aws.ToString(output.LogResult))
+	if err != nil {
+		return err
+	}
+	if logs != "" {
+		fmt.Fprintf(os.Stderr, "---\n%s---\n", logs)
+	}
+
+	switch input.InvocationType {
+	case lambdaTypes.InvocationTypeEvent:
+		fmt.Fprintf(os.Stderr, "Event queued (status code: %d)\n", output.StatusCode)
+	case lambdaTypes.InvocationTypeDryRun:
+		fmt.Fprintf(os.Stderr, "Dry run succeeded (status code: %d)\n", output.StatusCode)
+	default:
+		fmt.Println(formatResponse(output.Payload))
+	}
+
+	if output.FunctionError != nil {
+		return fmt.Errorf("function returned an error: %s", *output.FunctionError)
+	}
+
+	return nil
+}
+
+// Reads the invocation payload from inline data, a file, or stdin. Passing
+// "-" as the file reads stdin, as does piping into jeeves without either flag.
+func ReadPayload(data string, file string, stdin io.Reader) ([]byte, error) {
+	if data != "" && file != "" {
+		return nil, errors.New("only one of --data or --payload may be provided")
+	}
+
+	if data != "" {
+		return []byte(data), nil
+	}
+
+	if file == "-" {
+		return io.ReadAll(stdin)
+	}
+
+	if file != "" {
+		return os.ReadFile(file)
+	}
+
+	if f, ok := stdin.(*os.File); ok && isPiped(f) {
+		return io.ReadAll(f)
+	}
+
+	return nil, nil
+}
+
+func isPiped(f *os.File) bool {
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return stat.Mode()&os.ModeCharDevice == 0
+}
+
+func parseInvocationType(value string) (lambdaTypes.InvocationType, error) {
+	for _, invocationType := range lambdaTypes.InvocationTypeRequestResponse.Values() {
+		if string(invocationType) == value {
+			return invocationType, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid invocation type \"%s\", expected one of RequestResponse, Event or DryRun", value)
+}
+
+// The Lambda API expects the client context as base64 encoded JSON
+func encodeClientContext(clientContext string) (*string, error) {
+	if clientContext == "" {
+		return nil, nil
+	}
+
+	if !json.Valid([]byte(clientContext)) {
+		return nil, errors.New("client context must be valid JSON")
+	}
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(clientContext))
+	return &encoded, nil
+}
+
+// Decodes the base64 encoded tail of the execution log returned by the Lambda API
+func decodeLogResult(logResult string) (string, error) {
+	if logResult == "" {
+		return "", nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(logResult)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// Pretty prints JSON responses, anything else is returned as is
+func formatResponse(payload []byte) string {
+	var out bytes.Buffer
+	if err := json.Indent(&out, payload, "", "  "); err != nil {
+		return string(payload)
+	}
+
+	return out.String()
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
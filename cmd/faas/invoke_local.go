@@ -0,0 +1,268 @@
+package faas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/obscurelyme/jeeves/templates"
+)
+
+// Path the Lambda runtime interface emulator listens on for invocations
+const LOCAL_INVOKE_PATH string = "/2015-03-31/functions/function/invocations"
+
+// Host port the lambda service is published on
+const LOCAL_INVOKE_PORT int = templates.LAMBDA_HOST_PORT
+
+// A single event to send to the local runtime
+type LocalFixture struct {
+	Name    string
+	Payload []byte
+}
+
+type LocalInvocation struct {
+	Fixture    string
+	StatusCode int
+	Response   []byte
+	Duration   time.Duration
+	Logs       string
+}
+
+// Reports whether the runtime responded with a function error payload
+func (li *LocalInvocation) Failed() bool {
+	if li.StatusCode >= http.StatusBadRequest {
+		return true
+	}
+
+	var response struct {
+		ErrorType    string `json:"errorType"`
+		ErrorMessage string `json:"errorMessage"`
+	}
+	if err := json.Unmarshal(li.Response, &response); err != nil {
+		return false
+	}
+
+	return response.ErrorType != "" || response.ErrorMessage != ""
+}
+
+// Returns the container log output since the given time
+var ContainerLogs func(since time.Time) (string, error)
+
+func init() {
+	ContainerLogs = dockerComposeLogs
+}
+
+func localEndpoint(port int) string {
+	return fmt.Sprintf("http://localhost:%d%s", port, LOCAL_INVOKE_PATH)
+}
+
+func invokeLocalHandler(port int, wait time.Duration) error {
+	fixtures, err := ReadFixtures(invokeData, invokePayloadFile, os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	endpoint := localEndpoint(port)
+	err = WaitForLocalRuntime(endpoint, wait)
+	if err != nil {
+		return err
+	}
+
+	failures := 0
+	for _, fixture := range fixtures {
+		invocation, err := InvokeLocal(endpoint, fixture)
+		if err != nil {
+			return err
+		}
+
+		writeLocalInvocation(os.Stdout, invocation)
+		if invocation.Failed() {
+			failures++
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d of %d local invocations returned an error", failures, len(fixtures))
+	}
+
+	return nil
+}
+
+// Collects the events to invoke the local runtime with. A directory passed as
+// the payload yields one fixture per .json file, in name order.
+func ReadFixtures(data string, path string, stdin io.Reader) ([]LocalFixture, error) {
+	if path != "" && path != "-" {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if info.IsDir() {
+			return readFixtureDir(path)
+		}
+	}
+
+	payload, err := ReadPayload(data, path, stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	name := path
+	if name == "" || name == "-" {
+		name = "event"
+	}
+
+	return []LocalFixture{{Name: name, Payload: payload}}, nil
+}
+
+func readFixtureDir(dir string) ([]LocalFixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := []LocalFixture{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		payload, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		fixtures = append(fixtures, LocalFixture{Name: path, Payload: payload})
+	}
+
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("no .json event fixtures found in %s", dir)
+	}
+
+	sort.Slice(fixtures, func(i, j int) bool {
+		return fixtures[i].Name < fixtures[j].Name
+	})
+
+	return fixtures, nil
+}
+
+// Polls the runtime interface emulator until it accepts connections, the
+// container may still be building when "faas start" was just run.
+func WaitForLocalRuntime(endpoint string, timeout time.Duration) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		conn, err := net.DialTimeout("tcp", req.URL.Host, time.Second)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("local runtime at %s is not ready, is \"jeeves faas start\" running?", req.URL.Host)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// Posts the fixture to the local runtime and collects its response and the
+// container logs written while handling it.
+func InvokeLocal(endpoint string, fixture LocalFixture) (*LocalInvocation, error) {
+	payload := fixture.Payload
+	if len(payload) == 0 {
+		payload = []byte("{}")
+	}
+
+	start := time.Now()
+	res, err := http.Post(endpoint, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	invocation := &LocalInvocation{
+		Fixture:    fixture.Name,
+		StatusCode: res.StatusCode,
+		Response:   body,
+		Duration:   time.Since(start),
+	}
+
+	logs, err := ContainerLogs(start.Add(-time.Second))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not read container logs: %s\n", err.Error())
+		return invocation, nil
+	}
+	invocation.Logs = extractRequestLogs(logs)
+
+	return invocation, nil
+}
+
+// Returns the lines of the last request in the log output, from its START
+// line up to and including its REPORT line.
+func extractRequestLogs(logs string) string {
+	lines := strings.Split(strings.TrimRight(logs, "\n"), "\n")
+
+	start := -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.HasPrefix(lines[i], "START RequestId:") {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		return ""
+	}
+
+	end := len(lines) - 1
+	for i := start; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "REPORT RequestId:") {
+			end = i
+			break
+		}
+	}
+
+	return strings.Join(lines[start:end+1], "\n") + "\n"
+}
+
+func dockerComposeLogs(since time.Time) (string, error) {
+	var stdout, stderr bytes.Buffer
+	dockerCmd := exec.Command("docker", "compose", "logs", "--no-log-prefix", "--since", since.Format(time.RFC3339), "lambda")
+	dockerCmd.Dir = ConfigPath
+	dockerCmd.Stdout = &stdout
+	dockerCmd.Stderr = &stderr
+
+	if err := dockerCmd.Run(); err != nil {
+		return "", errors.New(strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+func writeLocalInvocation(w io.Writer, invocation *LocalInvocation) {
+	fmt.Fprintf(w, "--- %s (status: %d, duration: %s)\n", invocation.Fixture, invocation.StatusCode, invocation.Duration.Round(time.Millisecond))
+	if invocation.Logs != "" {
+		fmt.Fprint(w, invocation.Logs)
+		fmt.Fprintln(w, "---")
+	}
+	fmt.Fprintln(w, formatResponse(invocation.Response))
+}
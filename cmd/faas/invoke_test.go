@@ -0,0 +1,145 @@
+package faas
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInvokeFaaS(t *testing.T) {
+	t.Run("should read the payload from stdin when given \"-\"", func(t *testing.T) {
+		payload, err := ReadPayload("", "-", strings.NewReader(`{"key":"value"}`))
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if string(payload) != `{"key":"value"}` {
+			t.Errorf("expected payload to be read from stdin, but received \"%s\"", string(payload))
+		}
+	})
+
+	t.Run("should reject both --data and --payload", func(t *testing.T) {
+		_, err := ReadPayload("{}", "event.json", strings.NewReader(""))
+		if err == nil {
+			t.Errorf("expected an error when both --data and --payload are provided")
+		}
+	})
+
+	t.Run("should decode the log tail", func(t *testing.T) {
+		encoded := base64.StdEncoding.EncodeToString([]byte("START RequestId: 1234\n"))
+
+		logs, err := decodeLogResult(encoded)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if logs != "START RequestId: 1234\n" {
+			t.Errorf("log tail was not decoded, received \"%s\"", logs)
+		}
+	})
+
+	t.Run("should pretty print JSON responses", func(t *testing.T) {
+		response := formatResponse([]byte(`{"statusCode":200}`))
+
+		if response != "{\n  \"statusCode\": 200\n}" {
+			t.Errorf("response was not pretty printed, received \"%s\"", response)
+		}
+	})
+
+	t.Run("should leave non JSON responses untouched", func(t *testing.T) {
+		response := formatResponse([]byte("hello"))
+
+		if response != "hello" {
+			t.Errorf("expected \"hello\", but received \"%s\"", response)
+		}
+	})
+
+	t.Run("should reject unknown invocation types", func(t *testing.T) {
+		_, err := parseInvocationType("Sync")
+		if err == nil {
+			t.Errorf("expected an error for an invalid invocation type")
+		}
+	})
+}
+
+func TestInvokeLocalFaaS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != LOCAL_INVOKE_PATH {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "fail") {
+			w.Write([]byte(`{"errorType":"Error","errorMessage":"boom"}`))
+			return
+		}
+		w.Write([]byte(`{"statusCode":200}`))
+	}))
+	defer server.Close()
+
+	endpoint := server.URL + LOCAL_INVOKE_PATH
+	ContainerLogs = func(since time.Time) (string, error) {
+		return "START RequestId: 1 Version: $LATEST\nold\nREPORT RequestId: 1\nSTART RequestId: 2 Version: $LATEST\nhello\nEND RequestId: 2\nREPORT RequestId: 2\tDuration: 1.00 ms\n", nil
+	}
+
+	t.Run("should wait for the local runtime", func(t *testing.T) {
+		err := WaitForLocalRuntime(endpoint, time.Second)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+		}
+	})
+
+	t.Run("should post the event and collect the request logs", func(t *testing.T) {
+		invocation, err := InvokeLocal(endpoint, LocalFixture{Name: "event", Payload: []byte(`{}`)})
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if invocation.Failed() {
+			t.Errorf("expected the invocation to succeed, received \"%s\"", string(invocation.Response))
+		}
+
+		expectedLogs := "START RequestId: 2 Version: $LATEST\nhello\nEND RequestId: 2\nREPORT RequestId: 2\tDuration: 1.00 ms\n"
+		if invocation.Logs != expectedLogs {
+			t.Errorf("expected only the last request's logs, received \"%s\"", invocation.Logs)
+		}
+	})
+
+	t.Run("should report function errors", func(t *testing.T) {
+		invocation, err := InvokeLocal(endpoint, LocalFixture{Name: "event", Payload: []byte(`{"fail":true}`)})
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if !invocation.Failed() {
+			t.Errorf("expected the invocation to be reported as failed")
+		}
+	})
+
+	t.Run("should read a directory of fixtures in order", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		os.WriteFile(filepath.Join(tmpDir, "b.json"), []byte(`{"b":1}`), 0644)
+		os.WriteFile(filepath.Join(tmpDir, "a.json"), []byte(`{"a":1}`), 0644)
+		os.WriteFile(filepath.Join(tmpDir, "notes.txt"), []byte("ignored"), 0644)
+
+		fixtures, err := ReadFixtures("", tmpDir, strings.NewReader(""))
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if len(fixtures) != 2 || string(fixtures[0].Payload) != `{"a":1}` {
+			t.Errorf("expected two fixtures in name order, received %v", fixtures)
+		}
+	})
+}
@@ -0,0 +1,268 @@
+package faas
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/obscurelyme/jeeves/config"
+	"github.com/obscurelyme/jeeves/types"
+	"github.com/obscurelyme/jeeves/utils/archive"
+	"github.com/obscurelyme/jeeves/utils/java"
+	pythonUtils "github.com/obscurelyme/jeeves/utils/python"
+	"github.com/spf13/cobra"
+)
+
+const DEFAULT_LAYER_FILE string = "layer.zip"
+
+var layerRuntime string
+var layerArchitecture string
+var layerSource string
+var layerOutput string
+var layerRuntimes []string
+var layerArchitectures []string
+var layerDescription string
+var layerFaasCmd = &cobra.Command{
+	Use:   "layer",
+	Short: "Build and publish Lambda layers",
+	Long:  "Packages shared dependencies into a Lambda layer so they are not duplicated in every function zip",
+}
+
+var buildLayerCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Builds a layer zip",
+	Long: `Builds a layer zip from the dependencies of the FaaS resource in the current
+directory, using the directory layout the runtime expects:
+
+  nodejs    node_modules      -> nodejs/node_modules
+  python    .jeeves/python    -> python/
+  java      target/dependency -> java/lib
+  provided  bin               -> bin/
+
+Gradle projects use build/lambda/dependency instead of target/dependency.`,
+	Args: cobra.NoArgs,
+	RunE: buildLayerCmdHandler,
+}
+
+var publishLayerCmd = &cobra.Command{
+	Use:   "publish NAME",
+	Short: "Publishes a layer zip",
+	Long:  "Publishes a layer zip as a new version of the NAME layer",
+	Args:  cobra.ExactArgs(1),
+	RunE:  publishLayerCmdHandler,
+}
+
+func init() {
+	layerFaasCmd.PersistentFlags().StringVar(&profile, "profile", "default", "AWS Profile to work with")
+
+	buildLayerCmd.PersistentFlags().StringVar(&layerRuntime, "runtime", "", "Runtime of the layer, defaults to function.runtime of faas.yaml")
+	buildLayerCmd.PersistentFlags().StringVar(&layerArchitecture, "arch", "", "Architecture the python dependencies are installed for, arm64 or x86_64, defaults to function.architecture of faas.yaml")
+	buildLayerCmd.PersistentFlags().StringVar(&layerSource, "source", "", "Directory holding the dependencies, defaults to the runtime's dependency directory")
+	buildLayerCmd.PersistentFlags().StringVarP(&layerOutput, "output", "o", DEFAULT_LAYER_FILE, "Where to write the layer zip")
+
+	publishLayerCmd.PersistentFlags().StringVar(&layerOutput, "zip", DEFAULT_LAYER_FILE, "Layer zip to publish")
+	publishLayerCmd.PersistentFlags().StringArrayVar(&layerRuntimes, "runtime", []string{}, "Compatible runtime, may be repeated, defaults to function.runtime of faas.yaml")
+	publishLayerCmd.PersistentFlags().StringArrayVar(&layerArchitectures, "arch", []string{}, "Compatible architecture, arm64 or x86_64, may be repeated")
+	publishLayerCmd.PersistentFlags().StringVar(&layerDescription, "description", "", "Description of the layer version")
+
+	layerFaasCmd.AddCommand(buildLayerCmd)
+	layerFaasCmd.AddCommand(publishLayerCmd)
+}
+
+func buildLayerCmdHandler(cmd *cobra.Command, args []string) error {
+	runtime := layerRuntime
+	configuredArchitecture := ""
+	if faasConfig, err := ReadLambdaConfig(); err == nil {
+		configuredArchitecture = faasConfig.GetString("function.architecture")
+		if runtime == "" {
+			runtime = faasConfig.GetString("function.runtime")
+		}
+	} else if runtime == "" {
+		return fmt.Errorf("no runtime given, pass --runtime or run within a FaaS resource: %w", err)
+	}
+
+	// NOTE: python dependencies are installed for the layer's architecture unless a source is given
+	if strings.HasPrefix(runtime, "python") && layerSource == "" {
+		architecture, err := resolveArchitecture(layerArchitecture, configuredArchitecture, runtime)
+		if err != nil {
+			return err
+		}
+
+		err = VendorPythonDependencies(runtime, architecture)
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := BuildLayer(runtime, layerSource)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(layerOutput, data, 0644)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Layer written to %s (%d KB)\n", layerOutput, len(data)/1024)
+	return nil
+}
+
+func publishLayerCmdHandler(cmd *cobra.Command, args []string) error {
+	runtimes := layerRuntimes
+	if len(runtimes) == 0 {
+		faasConfig, err := ReadLambdaConfig()
+		if err == nil && faasConfig.GetString("function.runtime") != "" {
+			runtimes = []string{faasConfig.GetString("function.runtime")}
+		}
+	}
+
+	architectures := []lambdaTypes.Architecture{}
+	for _, value := range layerArchitectures {
+		architecture, err := types.ParseArchitecture(value)
+		if err != nil {
+			return err
+		}
+		architectures = append(architectures, architecture)
+	}
+
+	data, err := os.ReadFile(layerOutput)
+	if err != nil {
+		return fmt.Errorf("could not read %s, run \"jeeves faas layer build\" first: %w", layerOutput, err)
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	client := lambda.NewFromConfig(cfg)
+	output, err := client.PublishLayerVersion(context.TODO(), &lambda.PublishLayerVersionInput{
+		LayerName:               &args[0],
+		Content:                 &lambdaTypes.LayerVersionContentInput{ZipFile: data},
+		CompatibleRuntimes:      toRuntimes(runtimes),
+		CompatibleArchitectures: architectures,
+		Description:             optionalString(layerDescription),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Published %s\n", aws.ToString(output.LayerVersionArn))
+	return nil
+}
+
+// Zips the runtime's dependencies in the directory layout Lambda adds to the
+// runtime's search path, source overrides the default dependency directory.
+func BuildLayer(runtime string, source string) ([]byte, error) {
+	entries, err := layerEntries(runtime, source)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if _, err := os.Stat(entry.Source); err != nil {
+			return nil, fmt.Errorf("%s is missing, install the dependencies before building a layer", entry.Source)
+		}
+	}
+
+	return archive.Zip(entries)
+}
+
+func layerEntries(runtime string, source string) ([]archive.Entry, error) {
+	path := func(name string) string {
+		if source != "" {
+			return source
+		}
+		return filepath.Join(ConfigPath, name)
+	}
+
+	switch {
+	case strings.HasPrefix(runtime, "nodejs"):
+		return []archive.Entry{{Source: path("node_modules"), Target: "nodejs/node_modules"}}, nil
+	case strings.HasPrefix(runtime, "python"):
+		if source != "" {
+			return []archive.Entry{{Source: source, Target: "python"}}, nil
+		}
+		return []archive.Entry{{Source: path(pythonUtils.PACKAGES_DIR), Target: "python"}}, nil
+	case strings.HasPrefix(runtime, "java"):
+		project, err := java.DetectProject(ConfigPath)
+		if err != nil {
+			return nil, err
+		}
+		return []archive.Entry{{Source: path(project.DependencyPath()), Target: "java/lib"}}, nil
+	case strings.HasPrefix(runtime, "provided"):
+		return []archive.Entry{{Source: path("bin"), Target: "bin"}}, nil
+	}
+
+	return nil, fmt.Errorf("building layers for the \"%s\" runtime is not supported", runtime)
+}
+
+// Resolves layer names, or layer ARNs without a version, to the ARN of their
+// latest version. ARNs which already carry a version are used as is.
+func ResolveLayers(cfg aws.Config, layers []string) ([]string, error) {
+	client := lambda.NewFromConfig(cfg)
+	arns := []string{}
+
+	for _, layer := range layers {
+		if isLayerVersionArn(layer) {
+			arns = append(arns, layer)
+			continue
+		}
+
+		output, err := client.ListLayerVersions(context.TODO(), &lambda.ListLayerVersionsInput{
+			LayerName: &layer,
+			MaxItems:  aws.Int32(1),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// NOTE: versions are listed newest first
+		if len(output.LayerVersions) == 0 {
+			return nil, fmt.Errorf("layer %s has no published versions", layer)
+		}
+		arns = append(arns, aws.ToString(output.LayerVersions[0].LayerVersionArn))
+	}
+
+	return arns, nil
+}
+
+// Attaches the layers to $LATEST and waits for the update to finish
+func UpdateFaaSLayers(cfg aws.Config, name string, layers []string) error {
+	client := lambda.NewFromConfig(cfg)
+
+	_, err := client.UpdateFunctionConfiguration(context.TODO(), &lambda.UpdateFunctionConfigurationInput{
+		FunctionName: &name,
+		Layers:       layers,
+	})
+	if err != nil {
+		return err
+	}
+
+	waiter := lambda.NewFunctionUpdatedV2Waiter(client)
+	return waiter.Wait(context.TODO(), &lambda.GetFunctionInput{
+		FunctionName: &name,
+	}, DEFAULT_WAIT_DURATION)
+}
+
+// IE: arn:aws:lambda:us-east-1:123456789012:layer:my-layer:3
+func isLayerVersionArn(layer string) bool {
+	parts := strings.Split(layer, ":")
+	return len(parts) == 8 && parts[0] == "arn" && parts[5] == "layer"
+}
+
+func toRuntimes(values []string) []lambdaTypes.Runtime {
+	runtimes := []lambdaTypes.Runtime{}
+	for _, value := range values {
+		runtimes = append(runtimes, lambdaTypes.Runtime(value))
+	}
+
+	return runtimes
+}
@@ -0,0 +1,100 @@
+package faas
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func zipNames(t *testing.T, data []byte) []string {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("expected a valid zip, but received \"%s\"", err.Error())
+	}
+
+	names := []string{}
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+func TestBuildLayer(t *testing.T) {
+	tmpDir := t.TempDir()
+	ConfigPath = tmpDir
+
+	t.Run("should place node_modules below nodejs/", func(t *testing.T) {
+		os.MkdirAll(filepath.Join(tmpDir, "node_modules/left-pad"), 0755)
+		os.WriteFile(filepath.Join(tmpDir, "node_modules/left-pad/index.js"), []byte("module.exports = {}"), 0644)
+
+		data, err := BuildLayer("nodejs20.x", "")
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		expected := []string{"nodejs/node_modules/left-pad/index.js"}
+		if names := zipNames(t, data); !slices.Equal(names, expected) {
+			t.Errorf("expected %v, but received %v", expected, names)
+		}
+	})
+
+	t.Run("should place java dependencies below java/lib", func(t *testing.T) {
+		os.MkdirAll(filepath.Join(tmpDir, "target/dependency"), 0755)
+		os.WriteFile(filepath.Join(tmpDir, "target/dependency/gson.jar"), []byte("jar"), 0644)
+
+		data, err := BuildLayer("java21", "")
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		expected := []string{"java/lib/gson.jar"}
+		if names := zipNames(t, data); !slices.Equal(names, expected) {
+			t.Errorf("expected %v, but received %v", expected, names)
+		}
+	})
+
+	t.Run("should use the source directory for python", func(t *testing.T) {
+		source := filepath.Join(tmpDir, "site-packages")
+		os.MkdirAll(filepath.Join(source, "requests"), 0755)
+		os.WriteFile(filepath.Join(source, "requests/__init__.py"), []byte(""), 0644)
+
+		data, err := BuildLayer("python3.12", source)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		expected := []string{"python/requests/__init__.py"}
+		if names := zipNames(t, data); !slices.Equal(names, expected) {
+			t.Errorf("expected %v, but received %v", expected, names)
+		}
+	})
+
+	t.Run("should fail when the dependencies are missing", func(t *testing.T) {
+		_, err := BuildLayer("provided.al2023", "")
+		if err == nil {
+			t.Errorf("expected an error for a missing bin directory")
+		}
+	})
+}
+
+func TestIsLayerVersionArn(t *testing.T) {
+	t.Run("should only accept layer ARNs with a version", func(t *testing.T) {
+		if !isLayerVersionArn("arn:aws:lambda:us-east-1:123456789012:layer:shared:3") {
+			t.Errorf("expected a versioned layer ARN to be accepted")
+		}
+		if isLayerVersionArn("arn:aws:lambda:us-east-1:123456789012:layer:shared") {
+			t.Errorf("expected a layer ARN without a version to be resolved")
+		}
+		if isLayerVersionArn("shared") {
+			t.Errorf("expected a layer name to be resolved")
+		}
+	})
+}
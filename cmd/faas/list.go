@@ -6,10 +6,12 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/obscurelyme/jeeves/config"
+	"github.com/obscurelyme/jeeves/utils/tags"
 	"github.com/spf13/cobra"
 )
 
 var profile string
+var listManaged bool
 var listFaasCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available FaaS resources",
@@ -19,6 +21,7 @@ var listFaasCmd = &cobra.Command{
 
 func init() {
 	listFaasCmd.PersistentFlags().StringVar(&profile, "profile", "default", "AWS Profile to login to")
+	listFaasCmd.PersistentFlags().BoolVar(&listManaged, "managed", false, "Only list FaaS resources created by jeeves")
 }
 
 func listFaasCmdHandler(cmd *cobra.Command, args []string) error {
@@ -34,19 +37,35 @@ func ListLambdas() error {
 	}
 
 	lambdaClient := lambda.NewFromConfig(cfg)
-	output, err := lambdaClient.ListFunctions(context.TODO(), &lambda.ListFunctionsInput{})
+	paginator := lambda.NewListFunctionsPaginator(lambdaClient, &lambda.ListFunctionsInput{})
 
-	if err != nil {
-		return err
-	}
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return err
+		}
+
+		for _, lambdaFunction := range output.Functions {
+			if listManaged {
+				// NOTE: ListFunctions does not return tags
+				tagsOutput, err := lambdaClient.ListTags(context.TODO(), &lambda.ListTagsInput{
+					Resource: lambdaFunction.FunctionArn,
+				})
+				if err != nil {
+					return err
+				}
+				if !tags.IsManaged(tagsOutput.Tags) {
+					continue
+				}
+			}
 
-	for _, lambdaFunction := range output.Functions {
-		fmt.Printf(
-			"---\nFunction: %s\nRuntime: %v\nVersion: %s\n---\n",
-			*lambdaFunction.FunctionName,
-			lambdaFunction.Runtime,
-			*lambdaFunction.Version,
-		)
+			fmt.Printf(
+				"---\nFunction: %s\nRuntime: %v\nVersion: %s\n---\n",
+				*lambdaFunction.FunctionName,
+				lambdaFunction.Runtime,
+				*lambdaFunction.Version,
+			)
+		}
 	}
 
 	return nil
@@ -0,0 +1,367 @@
+package faas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	logsTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/manifoldco/promptui"
+	"github.com/obscurelyme/jeeves/config"
+	"github.com/spf13/cobra"
+)
+
+var logsFollow bool
+var logsSince time.Duration
+var logsFilter string
+var logsRequestId string
+var logsJson bool
+var logsFaasCmd = &cobra.Command{
+	Use:   "logs NAME",
+	Short: "Prints the logs of a FaaS resource",
+	Long: `Prints the CloudWatch logs of a FaaS resource grouped by request.
+With --follow new requests are printed as they complete.`,
+	Args: cobra.ExactArgs(1),
+	RunE: logsFaasCmdHandler,
+}
+
+var reportStyle = promptui.Styler(promptui.FGYellow, promptui.FGBold)
+var requestStyle = promptui.Styler(promptui.FGCyan)
+
+func init() {
+	logsFaasCmd.PersistentFlags().StringVar(&profile, "profile", "default", "AWS Profile to work with")
+	logsFaasCmd.PersistentFlags().BoolVarP(&logsFollow, "follow", "f", false, "Stream new log events as they arrive")
+	logsFaasCmd.PersistentFlags().DurationVar(&logsSince, "since", 10*time.Minute, "How far back to read logs, IE: 30m, 1h")
+	logsFaasCmd.PersistentFlags().StringVar(&logsFilter, "filter", "", "CloudWatch Logs filter pattern")
+	logsFaasCmd.PersistentFlags().StringVar(&logsRequestId, "request-id", "", "Only print the logs of the given request, cannot be combined with --filter")
+	logsFaasCmd.PersistentFlags().BoolVar(&logsJson, "json", false, "Print the logs as JSON")
+}
+
+type LogLine struct {
+	Timestamp time.Time `json:"timestamp"`
+	Stream    string    `json:"stream"`
+	Message   string    `json:"message"`
+}
+
+// Metrics parsed from the REPORT line Lambda writes at the end of every request
+type RequestReport struct {
+	Duration       float64  `json:"durationMs"`
+	BilledDuration float64  `json:"billedDurationMs"`
+	MemorySize     int      `json:"memorySizeMb"`
+	MaxMemoryUsed  int      `json:"maxMemoryUsedMb"`
+	InitDuration   *float64 `json:"initDurationMs,omitempty"`
+}
+
+type RequestLogs struct {
+	RequestId string         `json:"requestId"`
+	Stream    string         `json:"stream"`
+	Lines     []LogLine      `json:"lines"`
+	Report    *RequestReport `json:"report,omitempty"`
+}
+
+var requestIdPattern = regexp.MustCompile(`^(START|END|REPORT) RequestId: ([0-9a-fA-F-]+)`)
+var reportFieldPattern = regexp.MustCompile(`(Duration|Billed Duration|Memory Size|Max Memory Used|Init Duration): ([0-9.]+)`)
+
+// Groups log lines by request. Each log stream belongs to a single execution
+// environment, which handles one request at a time, so lines are attributed to
+// the request most recently started on the same stream.
+type logGrouper struct {
+	active  map[string]*RequestLogs
+	pending map[string][]LogLine
+}
+
+func newLogGrouper() *logGrouper {
+	return &logGrouper{
+		active:  map[string]*RequestLogs{},
+		pending: map[string][]LogLine{},
+	}
+}
+
+// Adds a line, returning the request once its REPORT line has been seen
+func (g *logGrouper) Add(line LogLine) *RequestLogs {
+	match := requestIdPattern.FindStringSubmatch(line.Message)
+
+	if match != nil && match[1] == "START" {
+		// NOTE: lines logged before START, such as INIT_START, belong to the first request
+		g.active[line.Stream] = &RequestLogs{
+			RequestId: match[2],
+			Stream:    line.Stream,
+			Lines:     append(g.pending[line.Stream], line),
+		}
+		delete(g.pending, line.Stream)
+		return nil
+	}
+
+	request, ok := g.active[line.Stream]
+	if !ok {
+		g.pending[line.Stream] = append(g.pending[line.Stream], line)
+		return nil
+	}
+
+	request.Lines = append(request.Lines, line)
+	if match != nil && match[1] == "REPORT" {
+		request.Report = parseReport(line.Message)
+		delete(g.active, line.Stream)
+		return request
+	}
+
+	return nil
+}
+
+// Returns requests which have not completed yet, IE: timed out or still running,
+// followed by any lines which could not be attributed to a request.
+func (g *logGrouper) Flush() []*RequestLogs {
+	requests := []*RequestLogs{}
+	for _, stream := range sortedKeys(g.active) {
+		requests = append(requests, g.active[stream])
+	}
+	for _, stream := range sortedKeys(g.pending) {
+		requests = append(requests, &RequestLogs{
+			Stream: stream,
+			Lines:  g.pending[stream],
+		})
+	}
+	g.active = map[string]*RequestLogs{}
+	g.pending = map[string][]LogLine{}
+
+	return requests
+}
+
+func parseReport(message string) *RequestReport {
+	report := new(RequestReport)
+
+	for _, field := range reportFieldPattern.FindAllStringSubmatch(message, -1) {
+		value, err := strconv.ParseFloat(field[2], 64)
+		if err != nil {
+			continue
+		}
+
+		switch field[1] {
+		case "Duration":
+			report.Duration = value
+		case "Billed Duration":
+			report.BilledDuration = value
+		case "Memory Size":
+			report.MemorySize = int(value)
+		case "Max Memory Used":
+			report.MaxMemoryUsed = int(value)
+		case "Init Duration":
+			report.InitDuration = &value
+		}
+	}
+
+	return report
+}
+
+func logGroupName(functionName string) string {
+	return fmt.Sprintf("/aws/lambda/%s", functionName)
+}
+
+func logsFaasCmdHandler(cmd *cobra.Command, args []string) error {
+	// NOTE: a filter pattern drops the START and REPORT lines requests are grouped by
+	if logsFilter != "" && logsRequestId != "" {
+		return errors.New("--filter and --request-id cannot be combined, the filtered lines are not grouped by request")
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cmd.SilenceUsage = true
+	if logsFollow {
+		return TailFaaSLogs(ctx, cfg, args[0])
+	}
+
+	requests, err := GetFaaSLogs(ctx, cfg, args[0], time.Now().Add(-logsSince))
+	if err != nil {
+		return err
+	}
+
+	if logsJson {
+		return writeJson(os.Stdout, requests)
+	}
+
+	for _, request := range requests {
+		writeRequestLogs(os.Stdout, request)
+	}
+	return nil
+}
+
+// Reads the log events of the function since the given time, grouped by request
+func GetFaaSLogs(ctx context.Context, cfg aws.Config, functionName string, since time.Time) ([]*RequestLogs, error) {
+	client := cloudwatchlogs.NewFromConfig(cfg)
+	grouper := newLogGrouper()
+	requests := []*RequestLogs{}
+
+	paginator := cloudwatchlogs.NewFilterLogEventsPaginator(client, &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName:  aws.String(logGroupName(functionName)),
+		StartTime:     aws.Int64(since.UnixMilli()),
+		FilterPattern: optionalString(logsFilter),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range page.Events {
+			request := grouper.Add(LogLine{
+				Timestamp: time.UnixMilli(aws.ToInt64(event.Timestamp)),
+				Stream:    aws.ToString(event.LogStreamName),
+				Message:   strings.TrimRight(aws.ToString(event.Message), "\n"),
+			})
+			if request != nil && matchesRequestId(request) {
+				requests = append(requests, request)
+			}
+		}
+	}
+
+	for _, request := range grouper.Flush() {
+		if matchesRequestId(request) {
+			requests = append(requests, request)
+		}
+	}
+
+	return requests, nil
+}
+
+// Streams the function's log events with CloudWatch Logs Live Tail until the
+// context is cancelled, printing each request once it completes.
+func TailFaaSLogs(ctx context.Context, cfg aws.Config, functionName string) error {
+	stsClient := sts.NewFromConfig(cfg)
+	identity, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return err
+	}
+
+	client := cloudwatchlogs.NewFromConfig(cfg)
+	logGroupArn := fmt.Sprintf("arn:aws:logs:%s:%s:log-group:%s", cfg.Region, aws.ToString(identity.Account), logGroupName(functionName))
+	output, err := client.StartLiveTail(ctx, &cloudwatchlogs.StartLiveTailInput{
+		LogGroupIdentifiers:   []string{logGroupArn},
+		LogEventFilterPattern: optionalString(logsFilter),
+	})
+	if err != nil {
+		return err
+	}
+
+	stream := output.GetStream()
+	defer stream.Close()
+
+	fmt.Fprintf(os.Stderr, "Tailing %s, press Ctrl+C to stop...\n", logGroupName(functionName))
+	grouper := newLogGrouper()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-stream.Events():
+			if !ok {
+				return stream.Err()
+			}
+
+			update, ok := event.(*logsTypes.StartLiveTailResponseStreamMemberSessionUpdate)
+			if !ok {
+				continue
+			}
+
+			for _, result := range update.Value.SessionResults {
+				line := LogLine{
+					Timestamp: time.UnixMilli(aws.ToInt64(result.Timestamp)),
+					Stream:    aws.ToString(result.LogStreamName),
+					Message:   strings.TrimRight(aws.ToString(result.Message), "\n"),
+				}
+
+				// NOTE: a filter pattern drops the START and REPORT lines needed to group by request
+				if logsFilter != "" {
+					writeLogLine(os.Stdout, line)
+					continue
+				}
+
+				request := grouper.Add(line)
+				if request == nil || !matchesRequestId(request) {
+					continue
+				}
+
+				if logsJson {
+					writeCompactJson(os.Stdout, request)
+				} else {
+					writeRequestLogs(os.Stdout, request)
+				}
+			}
+		}
+	}
+}
+
+func matchesRequestId(request *RequestLogs) bool {
+	return logsRequestId == "" || request.RequestId == logsRequestId
+}
+
+func writeCompactJson(w io.Writer, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func writeLogLine(w io.Writer, line LogLine) {
+	if logsJson {
+		writeCompactJson(w, line)
+		return
+	}
+
+	fmt.Fprintf(w, "%s %s %s\n", line.Timestamp.Local().Format("15:04:05.000"), requestStyle(line.Stream), line.Message)
+}
+
+func writeRequestLogs(w io.Writer, request *RequestLogs) {
+	if request.RequestId == "" {
+		fmt.Fprintln(w, requestStyle(fmt.Sprintf("=== %s", request.Stream)))
+	} else {
+		fmt.Fprintln(w, requestStyle(fmt.Sprintf("=== RequestId: %s (%s)", request.RequestId, request.Stream)))
+	}
+
+	for _, line := range request.Lines {
+		if strings.HasPrefix(line.Message, "REPORT") {
+			continue
+		}
+		fmt.Fprintf(w, "%s %s\n", line.Timestamp.Local().Format("15:04:05.000"), line.Message)
+	}
+
+	if request.Report != nil {
+		fmt.Fprintln(w, reportStyle(formatReport(request.Report)))
+	}
+}
+
+func formatReport(report *RequestReport) string {
+	summary := fmt.Sprintf(
+		"REPORT Duration: %.2f ms | Billed: %.0f ms | Memory: %d/%d MB",
+		report.Duration,
+		report.BilledDuration,
+		report.MaxMemoryUsed,
+		report.MemorySize,
+	)
+
+	if report.InitDuration != nil {
+		summary += fmt.Sprintf(" | Init: %.2f ms", *report.InitDuration)
+	}
+
+	return summary
+}
@@ -0,0 +1,77 @@
+package faas
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFaaSLogs(t *testing.T) {
+	t.Run("should group lines by request per log stream", func(t *testing.T) {
+		grouper := newLogGrouper()
+		lines := []LogLine{
+			{Stream: "a", Message: "INIT_START Runtime Version: nodejs:20.v13"},
+			{Stream: "a", Message: "START RequestId: 11111111-aaaa Version: $LATEST"},
+			{Stream: "b", Message: "START RequestId: 22222222-bbbb Version: $LATEST"},
+			{Stream: "a", Message: "hello from a"},
+			{Stream: "b", Message: "hello from b"},
+			{Stream: "a", Message: "END RequestId: 11111111-aaaa"},
+			{Stream: "a", Message: "REPORT RequestId: 11111111-aaaa\tDuration: 12.34 ms\tBilled Duration: 13 ms\tMemory Size: 128 MB\tMax Memory Used: 70 MB\tInit Duration: 150.50 ms"},
+		}
+
+		var completed *RequestLogs
+		for _, line := range lines {
+			if request := grouper.Add(line); request != nil {
+				completed = request
+			}
+		}
+
+		if completed == nil || completed.RequestId != "11111111-aaaa" {
+			t.Fatalf("expected request 11111111-aaaa to complete, received %v", completed)
+		}
+
+		if len(completed.Lines) != 5 {
+			t.Errorf("expected 5 lines including INIT_START, received %d", len(completed.Lines))
+		}
+
+		pending := grouper.Flush()
+		if len(pending) != 1 || pending[0].RequestId != "22222222-bbbb" {
+			t.Errorf("expected request 22222222-bbbb to still be running, received %v", pending)
+		}
+	})
+
+	t.Run("should parse REPORT lines", func(t *testing.T) {
+		report := parseReport("REPORT RequestId: 1\tDuration: 12.34 ms\tBilled Duration: 13 ms\tMemory Size: 128 MB\tMax Memory Used: 70 MB\tInit Duration: 150.50 ms")
+
+		if report.Duration != 12.34 || report.BilledDuration != 13 || report.MemorySize != 128 || report.MaxMemoryUsed != 70 {
+			t.Errorf("report fields were not parsed, received %+v", report)
+		}
+
+		if report.InitDuration == nil || *report.InitDuration != 150.50 {
+			t.Errorf("expected an init duration of 150.50")
+		}
+	})
+
+	t.Run("should omit the init duration on warm starts", func(t *testing.T) {
+		report := parseReport("REPORT RequestId: 1\tDuration: 1.00 ms\tBilled Duration: 1 ms\tMemory Size: 128 MB\tMax Memory Used: 70 MB")
+
+		if report.InitDuration != nil {
+			t.Errorf("expected no init duration")
+		}
+	})
+}
+
+func TestLogsFlags(t *testing.T) {
+	t.Run("should reject a filter combined with a request id", func(t *testing.T) {
+		logsFilter = "ERROR"
+		logsRequestId = "c6af9ac6-7b61-11e6-9a41-93e812345678"
+		defer func() {
+			logsFilter = ""
+			logsRequestId = ""
+		}()
+
+		err := logsFaasCmdHandler(logsFaasCmd, []string{"my-function"})
+		if err == nil || !strings.Contains(err.Error(), "--request-id") {
+			t.Errorf("expected an error combining --filter and --request-id, but received %v", err)
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package faas
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/obscurelyme/jeeves/utils/tags"
+	"github.com/spf13/cobra"
+)
+
+var force bool
+
+// Registers --force on destructive commands
+func addForceFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&force, "force", false, "Allow changes to FaaS resources which were not created by jeeves")
+}
+
+// Refuses to change functions jeeves did not create, IE: functions missing
+// the jeeves:managed tag, unless --force is given.
+func requireManaged(cfg aws.Config, name string) error {
+	if force {
+		return nil
+	}
+
+	client := lambda.NewFromConfig(cfg)
+	output, err := client.GetFunction(context.TODO(), &lambda.GetFunctionInput{
+		FunctionName: &name,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !tags.IsManaged(output.Tags) {
+		return fmt.Errorf("FaaS resource %s is not managed by jeeves, it has no %s tag, pass --force to continue", name, tags.TAG_MANAGED)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package faas
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/obscurelyme/jeeves/config"
+	"github.com/spf13/cobra"
+)
+
+// How long to wait for a function update to finish before giving up
+const DEFAULT_WAIT_DURATION time.Duration = 5 * time.Minute
+
+var publishDescription string
+var publishFaasCmd = &cobra.Command{
+	Use:   "publish NAME",
+	Short: "Publishes a new version of a FaaS resource",
+	Long:  "Publishes an immutable version from the current code and configuration of $LATEST",
+	Args:  cobra.ExactArgs(1),
+	RunE:  publishFaasCmdHandler,
+}
+
+func init() {
+	publishFaasCmd.PersistentFlags().StringVar(&profile, "profile", "default", "AWS Profile to work with")
+	publishFaasCmd.PersistentFlags().StringVar(&publishDescription, "description", "", "Description of the version")
+}
+
+func publishFaasCmdHandler(cmd *cobra.Command, args []string) error {
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	version, err := PublishFaaSVersion(cfg, args[0], publishDescription)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Published version %s of %s\n", version, args[0])
+	return nil
+}
+
+// Publishes $LATEST as a new version, returning the version number
+func PublishFaaSVersion(cfg aws.Config, name string, description string) (string, error) {
+	client := lambda.NewFromConfig(cfg)
+
+	// NOTE: publishing fails while a code or configuration update is still in progress
+	waiter := lambda.NewFunctionUpdatedV2Waiter(client)
+	err := waiter.Wait(context.TODO(), &lambda.GetFunctionInput{FunctionName: &name}, DEFAULT_WAIT_DURATION)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := client.PublishVersion(context.TODO(), &lambda.PublishVersionInput{
+		FunctionName: &name,
+		Description:  optionalString(description),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.ToString(output.Version), nil
+}
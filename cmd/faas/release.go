@@ -0,0 +1,240 @@
+package faas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatchTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/obscurelyme/jeeves/config"
+	"github.com/spf13/cobra"
+)
+
+// How often the Errors metric is checked while a release step is in progress
+const RELEASE_POLL_INTERVAL time.Duration = time.Minute
+
+var releaseAlias string
+var releaseVersion string
+var releaseCanary string
+var releaseStep string
+var releaseInterval time.Duration
+var releaseErrorThreshold float64
+var releaseFaasCmd = &cobra.Command{
+	Use:   "release NAME",
+	Short: "Shifts an alias to a new version of a FaaS resource",
+	Long: `Shifts the traffic of an alias to a new version in weighted steps, IE:
+
+  jeeves faas release my-function --alias live --canary 10% --step 10% --interval 5m
+
+The new version's Errors metric is watched during every step, the alias is
+rolled back to the previous version once the error threshold is reached.
+When --version is omitted the current $LATEST is published first.`,
+	Args: cobra.ExactArgs(1),
+	RunE: releaseFaasCmdHandler,
+}
+
+func init() {
+	releaseFaasCmd.PersistentFlags().StringVar(&profile, "profile", "default", "AWS Profile to work with")
+	releaseFaasCmd.PersistentFlags().StringVar(&releaseAlias, "alias", "live", "Alias to shift")
+	releaseFaasCmd.PersistentFlags().StringVar(&releaseVersion, "version", "", "Version to release, defaults to publishing $LATEST")
+	releaseFaasCmd.PersistentFlags().StringVar(&releaseCanary, "canary", "10%", "Share of traffic routed to the new version in the first step")
+	releaseFaasCmd.PersistentFlags().StringVar(&releaseStep, "step", "10%", "Share of traffic added in every following step")
+	releaseFaasCmd.PersistentFlags().DurationVar(&releaseInterval, "interval", 5*time.Minute, "How long every step lasts")
+	releaseFaasCmd.PersistentFlags().Float64Var(&releaseErrorThreshold, "error-threshold", 1, "Number of errors of the new version that triggers a rollback")
+}
+
+// Moves traffic of an alias between two versions and reports how the new one is doing
+type ReleaseDriver interface {
+	// Routes the given share of traffic to the canary version, the rest to the stable version
+	Route(stable string, canary string, weight float64) error
+	// Routes all traffic to the version
+	Promote(version string) error
+	// Number of errors the version produced since the given time
+	Errors(version string, since time.Time) (float64, error)
+}
+
+type ReleasePlan struct {
+	Stable    string
+	Canary    string
+	Weights   []float64
+	Interval  time.Duration
+	Threshold float64
+}
+
+type AliasReleaseDriver struct {
+	cfg   aws.Config
+	name  string
+	alias string
+}
+
+func (d *AliasReleaseDriver) Route(stable string, canary string, weight float64) error {
+	return UpdateFaaSAlias(d.cfg, d.name, d.alias, stable, map[string]float64{canary: weight})
+}
+
+func (d *AliasReleaseDriver) Promote(version string) error {
+	return UpdateFaaSAlias(d.cfg, d.name, d.alias, version, nil)
+}
+
+func (d *AliasReleaseDriver) Errors(version string, since time.Time) (float64, error) {
+	client := cloudwatch.NewFromConfig(d.cfg)
+	now := time.Now()
+
+	output, err := client.GetMetricStatistics(context.TODO(), &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String("AWS/Lambda"),
+		MetricName: aws.String("Errors"),
+		Dimensions: []cloudwatchTypes.Dimension{
+			{Name: aws.String("FunctionName"), Value: &d.name},
+			{Name: aws.String("Resource"), Value: aws.String(fmt.Sprintf("%s:%s", d.name, d.alias))},
+			{Name: aws.String("ExecutedVersion"), Value: &version},
+		},
+		StartTime:  &since,
+		EndTime:    &now,
+		Period:     aws.Int32(60),
+		Statistics: []cloudwatchTypes.Statistic{cloudwatchTypes.StatisticSum},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var count float64
+	for _, datapoint := range output.Datapoints {
+		count += aws.ToFloat64(datapoint.Sum)
+	}
+
+	return count, nil
+}
+
+func releaseFaasCmdHandler(cmd *cobra.Command, args []string) error {
+	canary, err := parsePercent(releaseCanary)
+	if err != nil {
+		return err
+	}
+
+	step, err := parsePercent(releaseStep)
+	if err != nil {
+		return err
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	client := lambda.NewFromConfig(cfg)
+	alias, err := client.GetAlias(context.TODO(), &lambda.GetAliasInput{
+		FunctionName: &args[0],
+		Name:         &releaseAlias,
+	})
+	if err != nil {
+		return err
+	}
+
+	version := releaseVersion
+	if version == "" {
+		version, err = PublishFaaSVersion(cfg, args[0], "")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Published version %s of %s\n", version, args[0])
+	}
+
+	stable := aws.ToString(alias.FunctionVersion)
+	if stable == version {
+		return fmt.Errorf("alias %s already points at version %s", releaseAlias, version)
+	}
+
+	cmd.SilenceUsage = true
+	return Release(&AliasReleaseDriver{cfg: cfg, name: args[0], alias: releaseAlias}, &ReleasePlan{
+		Stable:    stable,
+		Canary:    version,
+		Weights:   releaseWeights(canary, step),
+		Interval:  releaseInterval,
+		Threshold: releaseErrorThreshold,
+	}, time.Sleep)
+}
+
+// Walks the plan's weights, promoting the canary after the last step. Rolls
+// back to the stable version as soon as the canary reaches the error threshold.
+func Release(driver ReleaseDriver, plan *ReleasePlan, sleep func(time.Duration)) error {
+	for _, weight := range plan.Weights {
+		fmt.Printf("Routing %.0f%% of traffic to version %s\n", weight*100, plan.Canary)
+		err := driver.Route(plan.Stable, plan.Canary, weight)
+		if err != nil {
+			return rollback(driver, plan, err)
+		}
+
+		start := time.Now()
+		for waited := time.Duration(0); waited < plan.Interval; {
+			wait := min(RELEASE_POLL_INTERVAL, plan.Interval-waited)
+			sleep(wait)
+			waited += wait
+
+			errorCount, err := driver.Errors(plan.Canary, start)
+			if err != nil {
+				return rollback(driver, plan, err)
+			}
+
+			if errorCount >= plan.Threshold {
+				return rollback(driver, plan, fmt.Errorf("version %s reported %.0f errors", plan.Canary, errorCount))
+			}
+		}
+	}
+
+	err := driver.Promote(plan.Canary)
+	if err != nil {
+		return rollback(driver, plan, err)
+	}
+
+	fmt.Printf("Version %s now receives all traffic\n", plan.Canary)
+	return nil
+}
+
+func rollback(driver ReleaseDriver, plan *ReleasePlan, cause error) error {
+	fmt.Printf("Rolling back to version %s...\n", plan.Stable)
+
+	err := driver.Promote(plan.Stable)
+	if err != nil {
+		return errors.Join(cause, fmt.Errorf("rollback failed: %w", err))
+	}
+
+	return fmt.Errorf("release of version %s was rolled back: %w", plan.Canary, cause)
+}
+
+// Weights routed to the canary in every step, below 1 since the last step
+// promotes the canary instead. IE: canary 10%, step 30% -> 0.1, 0.4, 0.7
+func releaseWeights(canary float64, step float64) []float64 {
+	weights := []float64{}
+
+	for i := 0; step > 0 || i == 0; i++ {
+		// NOTE: round away floating point drift, 0.1 + 0.2 != 0.3
+		weight := math.Round((canary+float64(i)*step)*1000) / 1000
+		if weight >= 1 {
+			break
+		}
+		weights = append(weights, weight)
+	}
+
+	return weights
+}
+
+// Parses a percentage such as "10%" or "10" into a fraction, IE: 0.1
+func parsePercent(value string) (float64, error) {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage \"%s\"", value)
+	}
+
+	if percent <= 0 || percent > 100 {
+		return 0, fmt.Errorf("percentage \"%s\" must be greater than 0%% and at most 100%%", value)
+	}
+
+	return percent / 100, nil
+}
@@ -0,0 +1,91 @@
+package faas
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+type MockReleaseDriver struct {
+	routes   []float64
+	promoted []string
+	errors   map[int]float64
+}
+
+func (d *MockReleaseDriver) Route(stable string, canary string, weight float64) error {
+	d.routes = append(d.routes, weight)
+	return nil
+}
+
+func (d *MockReleaseDriver) Promote(version string) error {
+	d.promoted = append(d.promoted, version)
+	return nil
+}
+
+func (d *MockReleaseDriver) Errors(version string, since time.Time) (float64, error) {
+	return d.errors[len(d.routes)], nil
+}
+
+func TestReleaseFaaS(t *testing.T) {
+	plan := &ReleasePlan{
+		Stable:    "1",
+		Canary:    "2",
+		Weights:   []float64{0.1, 0.5},
+		Interval:  5 * time.Minute,
+		Threshold: 1,
+	}
+	sleep := func(time.Duration) {}
+
+	t.Run("should promote the canary after every step", func(t *testing.T) {
+		driver := &MockReleaseDriver{}
+
+		err := Release(driver, plan, sleep)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if !slices.Equal(driver.routes, []float64{0.1, 0.5}) || !slices.Equal(driver.promoted, []string{"2"}) {
+			t.Errorf("expected to route 10%% then 50%% and promote version 2, received %v %v", driver.routes, driver.promoted)
+		}
+	})
+
+	t.Run("should roll back when the error threshold is reached", func(t *testing.T) {
+		driver := &MockReleaseDriver{errors: map[int]float64{2: 3}}
+
+		err := Release(driver, plan, sleep)
+		if err == nil {
+			t.Errorf("expected the release to fail")
+			return
+		}
+
+		if !slices.Equal(driver.promoted, []string{"1"}) {
+			t.Errorf("expected to roll back to version 1, received %v", driver.promoted)
+		}
+	})
+
+	t.Run("should compute the weights of every step", func(t *testing.T) {
+		weights := releaseWeights(0.1, 0.3)
+
+		if !slices.Equal(weights, []float64{0.1, 0.4, 0.7}) {
+			t.Errorf("expected weights 0.1, 0.4, 0.7, received %v", weights)
+		}
+
+		weights = releaseWeights(0.1, 0.1)
+		if len(weights) != 9 || weights[8] != 0.9 {
+			t.Errorf("expected 9 steps up to 0.9, received %v", weights)
+		}
+	})
+
+	t.Run("should parse percentages", func(t *testing.T) {
+		canary, err := parsePercent("25%")
+		if err != nil || canary != 0.25 {
+			t.Errorf("expected 0.25, received %v", canary)
+		}
+
+		_, err = parsePercent("150%")
+		if err == nil {
+			t.Errorf("expected an error for a percentage over 100%%")
+		}
+	})
+}
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/manifoldco/promptui"
 	jeevesEnv "github.com/obscurelyme/jeeves/env"
 	"github.com/obscurelyme/jeeves/templates"
 	"github.com/obscurelyme/jeeves/templates/scripts/python"
+	"github.com/obscurelyme/jeeves/types"
 	"github.com/obscurelyme/jeeves/utils"
 	"github.com/obscurelyme/jeeves/utils/java"
 	pythonUtils "github.com/obscurelyme/jeeves/utils/python"
@@ -19,26 +22,65 @@ import (
 )
 
 const FAAS_CONFIG_FILE string = "faas.yaml"
-const COMPOSE_TEMPLATE string = `services:
-  lambda:
-    build: .
-    ports:
-      - 9000:8080
-    env_file:
-      - .env`
 
 var ConfigPath = "."
 var startFaasCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Starts a local FaaS resource",
-	Long:  "Starts a FaaS resource locally, using docker",
-	RunE:  startFaasCmdHandler,
+	Long: `Starts a FaaS resource locally, using docker.
+
+The lambda service of the compose file is generated on every start, services written by
+hand are kept. When the settings of the lambda service were edited by hand it is only
+replaced after showing the diff and confirming. Sidecars declared under function.sidecars
+of faas.yaml, dynamodb, localstack, redis or xray, run next to the function with its
+endpoint variables pointing at them.
+
+With --watch the sources of the runtime are watched, IE: src for nodejs, and every change
+runs the runtime's build step and syncs the build output into the running container.
+Changes to dependency manifests rebuild the image. The watched paths and build step
+may be overridden with function.watch.paths and function.watch.build of faas.yaml.
+
+With --debug the debugger of the runtime is exposed, node on 9229, java (JDWP) on 5005,
+go (delve) on 2345 and python (debugpy) on 5678, through docker-compose.debug.yaml and
+an attach configuration is added to .vscode/launch.json and .run/ for JetBrains IDEs.
+
+The Dockerfile is rendered from the templates of .jeeves/templates, the user's templates
+or the embedded ones, see "jeeves faas templates eject", with the variables of
+function.dockerfile.env set with ENV.
+
+With build: container of faas.yaml the Dockerfile builds the function and installs its
+dependencies within a builder stage for the function's architecture, instead of copying
+what was built on the host. Python dependencies are installed from uv.lock, poetry.lock,
+requirements.txt or pyproject.toml, the packages of a venv are not. Run with
+--regenerate after changing the build of an existing project.
+
+With host builds python dependencies are vendored into .jeeves/python from uv.lock,
+poetry.lock, requirements.txt, pyproject.toml or the project's venv, no venv needs to be
+active. Only manylinux wheels of the runtime's python and the function's architecture are
+installed, requirements without a wheel are installed within the Lambda image.
+
+Java functions are built with maven when pom.xml exists, or with gradle when
+build.gradle or build.gradle.kts exists.
+
+With --regenerate the Dockerfile is updated to the current templates, a Dockerfile
+edited by hand is only overwritten after showing the diff and confirming.`,
+	RunE: startFaasCmdHandler,
 }
 
 var CheckAWSLogin func() (bool, error)
+var startWatch bool
+var startDebug bool
+var startRegenerate bool
+
+// Asks whether the generated file, edited by hand, may be overwritten
+var ConfirmOverwrite func(name string) (bool, error)
 
 func init() {
 	CheckAWSLogin = utils.CheckAWSLogin
+	ConfirmOverwrite = promptOverwrite
+	startFaasCmd.PersistentFlags().BoolVar(&startDebug, "debug", false, "Run the function under a debugger and write VS Code and JetBrains attach configurations")
+	startFaasCmd.PersistentFlags().BoolVar(&startWatch, "watch", false, "Rebuild and reload the running function when its sources change")
+	startFaasCmd.PersistentFlags().BoolVar(&startRegenerate, "regenerate", false, "Update the generated Dockerfile to the current templates")
 }
 
 func startFaasCmdHandler(cmd *cobra.Command, args []string) error {
@@ -49,12 +91,35 @@ func startFaasCmdHandler(cmd *cobra.Command, args []string) error {
 
 	faasRuntime := faasConfig.GetString("function.runtime")
 	faasHandler := faasConfig.GetString("function.handler")
+	warnRuntimeDeprecation(faasRuntime)
+
+	faasArchitecture, err := resolveArchitecture("", faasConfig.GetString("function.architecture"), faasRuntime)
+	if err != nil {
+		return err
+	}
+	if faasArchitecture == "" {
+		faasArchitecture = types.DEFAULT_ARCHITECTURE
+	}
+	build, err := types.ParseBuildMode(faasConfig.GetString("function.build"))
+	if err != nil {
+		return err
+	}
+	// NOTE: container builds never copy what was built on the host
+	if build == types.BUILD_HOST {
+		if strings.HasPrefix(faasRuntime, "python") {
+			err = VendorPythonDependencies(faasRuntime, faasArchitecture)
+			if err != nil {
+				return err
+			}
+		}
+		warnNativeMismatches(faasRuntime, faasArchitecture)
+	}
 	isLoggedIn, _ := CheckAWSLogin()
 	if !isLoggedIn {
 		return utils.ErrNotLoggedIn
 	}
 
-	err = initializeDockerFiles(faasRuntime, faasHandler)
+	err = initializeDockerFiles(faasConfig, faasRuntime, faasHandler, faasArchitecture, faasConfig.GetStringSlice("function.sidecars"), startRegenerate)
 	if err != nil {
 		return err
 	}
@@ -64,6 +129,20 @@ func startFaasCmdHandler(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	debugTarget := DebugTarget{}
+	if startDebug {
+		debugTarget, err = initializeDebugFiles(faasConfig, faasRuntime, faasHandler, faasArchitecture)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Debugger listening on localhost:%d, attach with \"%s\"\n", debugTarget.Port, DEBUG_CONFIGURATION_NAME)
+	}
+
+	if startWatch {
+		// NOTE: images which build the sources themselves are rebuilt on every change
+		return watchFaaS(faasConfig, faasArchitecture, debugTarget.Dockerfile != "" || build == types.BUILD_CONTAINER)
+	}
+
 	return dockerCompose()
 }
 
@@ -92,99 +171,229 @@ func initializeEnvFile() error {
 	return envFile.WriteConfig()
 }
 
-func initializeDockerFiles(faasRuntime string, faasHandler string) error {
-	isConfigured := checkFaasDockerConfig()
+// Writes the Dockerfile pinned to the platform of the architecture, so local runs
+// match what is deployed regardless of the host, and merges the lambda service and
+// sidecars into the compose file. An existing Dockerfile is only updated with regenerate,
+// see writeComposeFile for the compose file.
+func initializeDockerFiles(faasConfig *viper.Viper, faasRuntime string, faasHandler string, faasArchitecture lambdaTypes.Architecture, sidecars []string, regenerate bool) error {
+	generated, state, err := templates.ReadGenerated(filepath.Join(ConfigPath, "Dockerfile"))
+	if err != nil {
+		return err
+	}
 
-	if !isConfigured {
-		err := writeDockerfile(faasRuntime, faasHandler)
+	switch {
+	case state == templates.GENERATED_MISSING || regenerate:
+		err = writeDockerfile(faasConfig, faasRuntime, faasHandler, faasArchitecture)
 		if err != nil {
 			return err
 		}
+	case state == templates.GENERATED_OUTDATED:
+		fmt.Printf("Dockerfile was generated from template v%d, run \"jeeves faas start --regenerate\" to update it to v%d\n", generated.Version, templates.TEMPLATE_VERSION)
+	default:
+		fmt.Println("Dockerfile already written to, will not overwrite")
+	}
 
-		err = writeComposeFile(faasRuntime)
-		if err != nil {
-			return err
-		}
-	} else {
-		fmt.Println("Dockerfile and docker-compose.yaml already written to, will not overwrite")
+	return writeComposeFile(faasRuntime, faasArchitecture, sidecars)
+}
+
+func writeDockerfile(faasConfig *viper.Viper, faasRuntime string, faasHandler string, faasArchitecture lambdaTypes.Architecture) error {
+	dockerFile, err := newDockerfile(faasConfig, faasRuntime, faasHandler, faasArchitecture, false)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return writeGenerated(dockerFile.Path(), dockerFile.Content())
 }
 
-// Very naive, just stating the Dockerfile and the compose.yaml file[s] to see if they exist.
-func checkFaasDockerConfig() bool {
-	dockerFile := fmt.Sprintf("%s/Dockerfile", ConfigPath)
-	composeFiles := []string{
-		fmt.Sprintf("%s/docker-compose.yaml", ConfigPath),
-		fmt.Sprintf("%s/docker-compose.yml", ConfigPath),
-		fmt.Sprintf("%s/compose.yaml", ConfigPath),
-		fmt.Sprintf("%s/compose.yml", ConfigPath),
+// Renders the Dockerfile of the function, with deploy the image deployed to Lambda
+// which leaves out the debug tooling of local runs
+func newDockerfile(faasConfig *viper.Viper, faasRuntime string, faasHandler string, faasArchitecture lambdaTypes.Architecture, deploy bool) (templates.DockerFileWriter, error) {
+	var pythonDependencies pythonUtils.PythonDependencyDriver = nil
+	var javaProject *java.JavaProject = nil
+	var javaBuildDriver java.BuildFileDriver = nil
+
+	build, err := types.ParseBuildMode(faasConfig.GetString("function.build"))
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(faasRuntime, "python") {
+		// NOTE: container builds install the dependencies themselves
+		if build == types.BUILD_HOST {
+			pythonDependencies = &pythonUtils.PythonProject{Dir: ConfigPath}
+		} else {
+			project, err := pythonUtils.DetectProject(ConfigPath)
+			if err != nil {
+				return nil, err
+			}
+			// NOTE: the builder stage has no access to the packages of the venv on the host
+			if project.Manager == pythonUtils.MANAGER_VENV {
+				return nil, fmt.Errorf("container builds of python projects install the dependencies of uv.lock, poetry.lock, requirements.txt or pyproject.toml, declare the packages of %s in one of them", project.Venv)
+			}
+		}
+		// NOTE: write the bootstrap file, deployed images keep the bootstrap of the runtime
+		if !deploy {
+			script := python.New(ConfigPath, lambdaTypes.Runtime(faasRuntime))
+			err := script.WriteFile()
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	_, err := os.Stat(dockerFile)
+	if strings.Contains(faasRuntime, "java") {
+		javaProject, err = java.DetectProject(ConfigPath)
+		if err != nil {
+			return nil, err
+		}
+
+		// NOTE: the maven builder stage needs no plugin, gradle always needs the task of jeeves
+		if build == types.BUILD_HOST || javaProject.Tool == java.BUILD_TOOL_GRADLE {
+			javaBuildDriver, err = javaProject.BuildFileDriver()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if build == types.BUILD_CONTAINER && javaProject.Tool == java.BUILD_TOOL_GRADLE && !javaProject.HasWrapper() {
+			return nil, errors.New("container builds of gradle projects require the gradle wrapper, add it with \"gradle wrapper\"")
+		}
+	}
+
+	env, err := dockerfileEnv(faasConfig)
 	if err != nil {
-		return false
+		return nil, err
 	}
 
-	for _, composeFile := range composeFiles {
-		_, err := os.Stat(composeFile)
-		if err == nil {
-			return true
+	return templates.NewDockerFile(&templates.NewDockerFileInput{
+		Runtime:            faasRuntime,
+		Handler:            faasHandler,
+		Architecture:       faasArchitecture,
+		FilePath:           ConfigPath,
+		Env:                env,
+		Build:              build,
+		PythonDependencies: pythonDependencies,
+		JavaProject:        javaProject,
+		JavaBuildDriver:    javaBuildDriver,
+		Deploy:             deploy,
+	})
+}
+
+// Variables of function.dockerfile.env of faas.yaml, set with ENV in the image,
+// IE: - PIP_INDEX_URL=https://pypi.example.com/simple
+func dockerfileEnv(faasConfig *viper.Viper) (map[string]string, error) {
+	env := map[string]string{}
+
+	for _, variable := range faasConfig.GetStringSlice("function.dockerfile.env") {
+		name, value, found := strings.Cut(variable, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid variable \"%s\" of function.dockerfile.env, expected NAME=value", variable)
 		}
+		env[name] = value
 	}
 
-	return false
+	return env, nil
 }
 
-func writeDockerfile(faasRuntime string, faasHandler string) error {
-	var dockerFile templates.DockerFileWriter
-	var venv *pythonUtils.PythonVirtualEnv = nil
-	var mvnPomDriver *java.MavenPomFileDriver = nil
-	var mvnErr error = nil
+// Merges the lambda service and sidecars into the compose file, keeping the services
+// and settings written by hand. When the settings of the lambda service itself were
+// edited by hand the merged file is only written after showing the diff and confirming.
+func writeComposeFile(faasRuntime string, faasArchitecture lambdaTypes.Architecture, sidecars []string) error {
+	compose, err := templates.NewComposeFile(&templates.NewComposeFileInput{
+		Runtime:      faasRuntime,
+		Architecture: faasArchitecture,
+		Sidecars:     sidecars,
+	})
+	if err != nil {
+		return err
+	}
 
-	if strings.Contains(faasRuntime, "python") {
-		venv = pythonUtils.NewPythonVirtualEnv()
-		// Ensure we have an active venv
-		// NOTE: This could most likely be removed
-		if !pythonUtils.VirtualEnvActive() {
-			return errors.New("no python venv is active")
+	composeTemplate := templates.NewComposeTemplate(ConfigPath)
+	path := filepath.Join(ConfigPath, composeTemplate.FileName)
+
+	_, state, err := templates.ReadGenerated(path)
+	if err != nil {
+		return err
+	}
+
+	err = composeTemplate.ReadInConfig()
+	if err != nil {
+		return err
+	}
+
+	// NOTE: the lambda service is compared against the one generated for the sidecars
+	// already in the file, declaring a new sidecar is not an edit by hand
+	edited := false
+	if state == templates.GENERATED_EDITED || state == templates.GENERATED_UNTRACKED {
+		previous, err := templates.NewComposeFile(&templates.NewComposeFileInput{
+			Runtime:      faasRuntime,
+			Architecture: faasArchitecture,
+			Sidecars:     composeTemplate.Sidecars(),
+		})
+		if err != nil {
+			return err
 		}
-		// NOTE: write the bootstrap file
-		script := python.New(ConfigPath, lambdaTypes.Runtime(faasRuntime))
-		err := script.WriteFile()
+
+		edited, err = composeTemplate.LambdaChanged(previous)
 		if err != nil {
 			return err
 		}
 	}
 
-	if strings.Contains(faasRuntime, "java") {
-		fmt.Println("JAVA DETECTEED")
-		mvnPomDriver, mvnErr = java.New(ConfigPath)
-		if mvnErr != nil {
-			return mvnErr
-		}
+	err = composeTemplate.Merge(compose)
+	if err != nil {
+		return err
 	}
 
-	dockerFile, err := templates.NewDockerFile(&templates.NewDockerFileInput{
-		Runtime:        faasRuntime,
-		Handler:        faasHandler,
-		FilePath:       ConfigPath,
-		VirtualEnv:     venv,
-		MavenPomDriver: mvnPomDriver,
-	})
+	content, err := composeTemplate.Content()
+	if err != nil {
+		return err
+	}
+
+	if edited {
+		return writeGenerated(path, content)
+	}
+
+	return os.WriteFile(path, []byte(content), 0644)
+}
 
+// Writes the generated file. Files edited by hand, or written before they carried
+// a header, are only overwritten after showing the diff and confirming.
+func writeGenerated(path string, content string) error {
+	current, state, err := templates.ReadGenerated(path)
 	if err != nil {
 		return err
 	}
 
-	return dockerFile.WriteFile()
+	proposed := templates.ParseGenerated(content)
+	if (state == templates.GENERATED_EDITED || state == templates.GENERATED_UNTRACKED) && current.Body != proposed.Body {
+		name, _ := filepath.Rel(ConfigPath, path)
+		fmt.Print(templates.Diff(name, current.Body, proposed.Body))
+
+		confirmed, err := ConfirmOverwrite(name)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Printf("Keeping %s as is\n", name)
+			return nil
+		}
+	}
+
+	return os.WriteFile(path, []byte(content), 0644)
 }
 
-func writeComposeFile(faasRuntime string) error {
-	// TODO: need to work with the runtime param to determine what kind of compose file the user gets
-	composeFilePath := fmt.Sprintf("%s/docker-compose.yaml", ConfigPath)
-	return os.WriteFile(composeFilePath, []byte(COMPOSE_TEMPLATE), 0644)
+func promptOverwrite(name string) (bool, error) {
+	prompt := promptui.Prompt{
+		Label:     fmt.Sprintf("%s was edited by hand, overwrite it", name),
+		IsConfirm: true,
+	}
+
+	result, err := prompt.Run()
+	if errors.Is(err, promptui.ErrAbort) {
+		return false, nil
+	}
+
+	return strings.ToLower(result) == "y", err
 }
 
 func ReadLambdaConfig() (*viper.Viper, error) {
@@ -196,6 +405,19 @@ func ReadLambdaConfig() (*viper.Viper, error) {
 	return config, err
 }
 
+// Reads faas.yaml for commands whose settings may come from flags alone, a missing
+// faas.yaml reads as an empty one while an invalid one is still an error
+func ReadOptionalLambdaConfig() (*viper.Viper, error) {
+	config, err := ReadLambdaConfig()
+
+	var notFound viper.ConfigFileNotFoundError
+	if errors.As(err, &notFound) {
+		return viper.New(), nil
+	}
+
+	return config, err
+}
+
 func dockerCompose() error {
 	dockerCmd := exec.Command("docker", "compose", "up", "--build")
 
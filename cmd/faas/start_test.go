@@ -1,9 +1,15 @@
 package faas
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/obscurelyme/jeeves/templates"
+	"github.com/spf13/viper"
 )
 
 const nodejsYaml = `function:
@@ -25,6 +31,16 @@ func setup(tmpDir string, yamlFile string) {
 	os.WriteFile(filepath, []byte(yamlFile), 0644)
 }
 
+// Reads the faas.yaml written by setup
+func lambdaConfig(t *testing.T) *viper.Viper {
+	faasConfig, err := ReadLambdaConfig()
+	if err != nil {
+		t.Fatalf("expected no errors reading faas.yaml, but received \"%s\"", err.Error())
+	}
+
+	return faasConfig
+}
+
 func readFile(tmpDir string, filename string) (string, error) {
 	filepath := fmt.Sprintf("%s/%s", tmpDir, filename)
 	data, err := os.ReadFile(filepath)
@@ -37,17 +53,30 @@ func TestStartFaaS(t *testing.T) {
 	ConfigPath = tmpDir
 
 	t.Run("should write up a Dockerfile and docker-compose.yaml file for nodejs", func(t *testing.T) {
-		const expectedDockerFile = `FROM amazon/aws-lambda-nodejs:20
+		const expectedDockerFile = `FROM --platform=linux/amd64 amazon/aws-lambda-nodejs:20
 
 COPY node_modules ${LAMBDA_TASK_ROOT}/node_modules
 COPY dist ${LAMBDA_TASK_ROOT}/dist
 COPY package.json ${LAMBDA_TASK_ROOT}
 
 CMD [ "dist/index.js" ]`
+		const expectedComposeFile = `services:
+  lambda:
+    build: .
+    platform: linux/amd64
+    ports:
+    - "9000:8080"
+    env_file:
+    - .env
+    environment:
+      NODE_OPTIONS: --enable-source-maps
+    labels:
+      jeeves.managed: "true"
+`
 
 		setup(tmpDir, nodejsYaml)
 
-		err := initializeDockerFiles("nodejs20.x", "dist/index.js")
+		err := initializeDockerFiles(lambdaConfig(t), "nodejs20.x", "dist/index.js", "x86_64", nil, false)
 		if err != nil {
 			t.Errorf("expected no errors, but received \"%s\"", err.Error())
 			return
@@ -64,13 +93,193 @@ CMD [ "dist/index.js" ]`
 			return
 		}
 
-		if composeFile != COMPOSE_TEMPLATE {
-			t.Errorf("compose file written did not match the template")
+		if generated := templates.ParseGenerated(composeFile); generated.State() != templates.GENERATED_CURRENT || generated.Body != expectedComposeFile {
+			t.Errorf("compose file written did not match the expected value\n%s", composeFile)
 			return
 		}
 
-		if dockerFile != expectedDockerFile {
+		if generated := templates.ParseGenerated(dockerFile); generated.State() != templates.GENERATED_CURRENT || generated.Body != expectedDockerFile {
 			t.Errorf("dockerfile written did not match the expected value")
 		}
 	})
 }
+
+// Dockerfile generated from an older template and not edited since
+func outdatedDockerfile() string {
+	return strings.Replace(templates.Stamp("FROM amazon/aws-lambda-nodejs:20\n"), fmt.Sprintf("template v%d ", templates.TEMPLATE_VERSION), "template v0 ", 1)
+}
+
+func TestRegenerateFaaS(t *testing.T) {
+	tmpDir := t.TempDir()
+	ConfigPath = tmpDir
+	setup(tmpDir, nodejsYaml)
+	dockerfilePath := filepath.Join(tmpDir, "Dockerfile")
+
+	confirmed := false
+	ConfirmOverwrite = func(name string) (bool, error) {
+		return confirmed, nil
+	}
+
+	err := initializeDockerFiles(lambdaConfig(t), "nodejs20.x", "dist/index.js", "x86_64", nil, false)
+	if err != nil {
+		t.Errorf("expected no errors, but received \"%s\"", err.Error())
+		return
+	}
+
+	t.Run("should not touch an existing Dockerfile without regenerate", func(t *testing.T) {
+		os.WriteFile(dockerfilePath, []byte("FROM scratch\n"), 0644)
+
+		err := initializeDockerFiles(lambdaConfig(t), "nodejs22.x", "dist/index.js", "x86_64", nil, false)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if dockerFile, _ := readFile(tmpDir, "Dockerfile"); dockerFile != "FROM scratch\n" {
+			t.Errorf("expected the Dockerfile to be kept, but received\n%s", dockerFile)
+		}
+	})
+
+	t.Run("should keep a Dockerfile edited by hand unless confirmed", func(t *testing.T) {
+		err := initializeDockerFiles(lambdaConfig(t), "nodejs22.x", "dist/index.js", "x86_64", nil, true)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if dockerFile, _ := readFile(tmpDir, "Dockerfile"); dockerFile != "FROM scratch\n" {
+			t.Errorf("expected the Dockerfile to be kept, but received\n%s", dockerFile)
+		}
+
+		confirmed = true
+		err = initializeDockerFiles(lambdaConfig(t), "nodejs22.x", "dist/index.js", "x86_64", nil, true)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if dockerFile, _ := readFile(tmpDir, "Dockerfile"); !strings.Contains(dockerFile, "amazon/aws-lambda-nodejs:22") {
+			t.Errorf("expected the Dockerfile to be regenerated, but received\n%s", dockerFile)
+		}
+	})
+
+	t.Run("should update an untouched Dockerfile without asking", func(t *testing.T) {
+		confirmed = false
+		os.WriteFile(dockerfilePath, []byte(outdatedDockerfile()), 0644)
+
+		err := initializeDockerFiles(lambdaConfig(t), "nodejs22.x", "dist/index.js", "x86_64", nil, true)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if _, state, _ := templates.ReadGenerated(dockerfilePath); state != templates.GENERATED_CURRENT {
+			t.Errorf("expected the Dockerfile to be regenerated, but it is %s", state)
+		}
+	})
+
+	t.Run("should merge into a compose file with services written by hand", func(t *testing.T) {
+		composePath := filepath.Join(tmpDir, "docker-compose.yaml")
+		composeFile, _ := readFile(tmpDir, "docker-compose.yaml")
+		os.WriteFile(composePath, []byte(composeFile+"  postgres:\n    image: postgres:16\n"), 0644)
+
+		err := initializeDockerFiles(lambdaConfig(t), "nodejs22.x", "dist/index.js", "x86_64", []string{"localstack"}, false)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if composeFile, _ := readFile(tmpDir, "docker-compose.yaml"); !strings.Contains(composeFile, "postgres:16") || !strings.Contains(composeFile, "localstack:") {
+			t.Errorf("expected the compose file to be merged into, but received\n%s", composeFile)
+		}
+	})
+
+	t.Run("should keep a lambda service edited by hand unless confirmed", func(t *testing.T) {
+		composePath := filepath.Join(tmpDir, "docker-compose.yaml")
+		composeFile, _ := readFile(tmpDir, "docker-compose.yaml")
+		edited := strings.Replace(composeFile, "9000:8080", "9100:8080", 1)
+		os.WriteFile(composePath, []byte(edited), 0644)
+
+		err := initializeDockerFiles(lambdaConfig(t), "nodejs22.x", "dist/index.js", "x86_64", []string{"redis"}, false)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if composeFile, _ := readFile(tmpDir, "docker-compose.yaml"); composeFile != edited {
+			t.Errorf("expected the compose file to be kept, but received\n%s", composeFile)
+		}
+
+		confirmed = true
+		err = initializeDockerFiles(lambdaConfig(t), "nodejs22.x", "dist/index.js", "x86_64", []string{"redis"}, false)
+		confirmed = false
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if composeFile, _ := readFile(tmpDir, "docker-compose.yaml"); !strings.Contains(composeFile, "redis") {
+			t.Errorf("expected the compose file to be merged into, but received\n%s", composeFile)
+		}
+	})
+
+	t.Run("should report the state of the generated files", func(t *testing.T) {
+		os.WriteFile(dockerfilePath, []byte(outdatedDockerfile()), 0644)
+		composeFile, _ := readFile(tmpDir, "docker-compose.yaml")
+		os.WriteFile(filepath.Join(tmpDir, "docker-compose.yaml"), []byte(composeFile+"  postgres:\n    image: postgres:16\n"), 0644)
+
+		var out bytes.Buffer
+		err := TemplatesStatus(&out)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		for _, expected := range []string{"Dockerfile           v0        outdated", fmt.Sprintf("docker-compose.yaml  v%d        edited", templates.TEMPLATE_VERSION), "--regenerate"} {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("expected the status to contain \"%s\", but received\n%s", expected, out.String())
+			}
+		}
+	})
+}
+
+func TestContainerBuildFaaS(t *testing.T) {
+	tmpDir := t.TempDir()
+	ConfigPath = tmpDir
+	t.Setenv("VIRTUAL_ENV", "")
+
+	t.Run("should build python within docker without an active venv", func(t *testing.T) {
+		setup(tmpDir, pythonYaml+"\n  build: container")
+
+		err := initializeDockerFiles(lambdaConfig(t), "python3.12", "pkg.main.handler", "arm64", nil, false)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		dockerFile, err := readFile(tmpDir, "Dockerfile")
+		if err != nil {
+			t.Errorf("expected no errors reading Dockerfile, but received \"%s\"", err.Error())
+			return
+		}
+		if !strings.Contains(dockerFile, "FROM --platform=linux/arm64 python:3.12 AS build") {
+			t.Errorf("expected a builder stage, but received\n%s", dockerFile)
+		}
+	})
+
+	t.Run("should reject python dependencies only installed into a venv", func(t *testing.T) {
+		os.Remove(filepath.Join(tmpDir, "Dockerfile"))
+		os.MkdirAll(filepath.Join(tmpDir, ".venv"), 0755)
+		os.WriteFile(filepath.Join(tmpDir, ".venv", "pyvenv.cfg"), []byte("version = 3.12.1\n"), 0644)
+		defer os.RemoveAll(filepath.Join(tmpDir, ".venv"))
+
+		err := initializeDockerFiles(lambdaConfig(t), "python3.12", "pkg.main.handler", "arm64", nil, false)
+		if err == nil || !strings.Contains(err.Error(), ".venv") {
+			t.Errorf("expected an error naming the venv, but received %v", err)
+		}
+	})
+
+	t.Run("should reject unknown builds", func(t *testing.T) {
+		os.Remove(filepath.Join(tmpDir, "Dockerfile"))
+		setup(tmpDir, pythonYaml+"\n  build: docker")
+
+		err := initializeDockerFiles(lambdaConfig(t), "python3.12", "pkg.main.handler", "arm64", nil, false)
+		if err == nil {
+			t.Errorf("expected an error for an unknown build")
+		}
+	})
+}
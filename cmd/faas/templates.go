@@ -0,0 +1,180 @@
+package faas
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/obscurelyme/jeeves/templates"
+	"github.com/obscurelyme/jeeves/types"
+	"github.com/spf13/cobra"
+)
+
+var templatesFaasCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Manage the files generated from templates",
+	Long:  "Manage the Dockerfile, compose file and debug files generated from the templates of jeeves",
+}
+
+var templatesStatusFaasCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Reports which generated files are outdated or edited",
+	Long: `Reports the state of every file generated from a template:
+current    generated from the current templates
+outdated   generated from older templates, "jeeves faas start --regenerate" updates it
+edited     edited by hand since it was generated
+untracked  written by hand or before generated files carried a header
+missing    not generated yet`,
+	RunE: templatesStatusFaasCmdHandler,
+}
+
+var templatesEjectUser bool
+var templatesEjectFaasCmd = &cobra.Command{
+	Use:   "eject [runtime...]",
+	Short: "Copies the embedded Dockerfile templates for editing",
+	Long: `Copies the embedded Dockerfile templates of the runtimes, for both host and container
+builds, or every template when no runtime is given, into .jeeves/templates of the project
+or with --user into the user's template directory. Templates of the project take
+precedence over the user's, which take precedence over the embedded ones. Existing
+templates are never overwritten.
+
+Templates are rendered with text/template and may use:
+{{ .Runtime }}, {{ .Language }}, {{ .Handler }}, {{ .Image }}, {{ .Tag }},
+{{ .Architecture }}, {{ .Platform }} IE: linux/arm64, {{ .GoArch }} IE: amd64,
+{{ .Build }}, {{ .Builder }} the image of builder stages,
+{{ .DepsPath }} for python and java, {{ .ClassesPath }} for java,
+{{ .DebugPort }} for debug images, {{ .Deploy }} when rendering the image deployed to Lambda
+and {{ .Env }} from function.dockerfile.env of faas.yaml`,
+	RunE: templatesEjectFaasCmdHandler,
+}
+
+func init() {
+	templatesEjectFaasCmd.PersistentFlags().BoolVar(&templatesEjectUser, "user", false, "Copy the templates into the user's template directory instead of the project")
+	templatesFaasCmd.AddCommand(templatesStatusFaasCmd)
+	templatesFaasCmd.AddCommand(templatesEjectFaasCmd)
+}
+
+func templatesStatusFaasCmdHandler(cmd *cobra.Command, args []string) error {
+	return TemplatesStatus(os.Stdout)
+}
+
+func templatesEjectFaasCmdHandler(cmd *cobra.Command, args []string) error {
+	names, err := ejectedTemplateNames(args)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(ConfigPath, templates.PROJECT_TEMPLATE_DIR)
+	if templatesEjectUser {
+		dir, err = templates.UserTemplateDir()
+		if err != nil {
+			return err
+		}
+	}
+
+	cmd.SilenceUsage = true
+	return EjectTemplates(dir, names)
+}
+
+// Templates of the runtimes, every embedded template without runtimes
+func ejectedTemplateNames(runtimes []string) ([]string, error) {
+	if len(runtimes) == 0 {
+		return templates.TemplateNames(), nil
+	}
+
+	names := []string{}
+	for _, runtime := range runtimes {
+		for _, build := range []types.BuildMode{types.BUILD_HOST, types.BUILD_CONTAINER} {
+			name, err := templates.DockerTemplateName(runtime, build)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", runtime, err)
+			}
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+
+		// NOTE: go functions are debugged with their own Dockerfile.debug
+		info, _ := types.LookupRuntime(runtime)
+		if info.Language == types.Golang && !slices.Contains(names, templates.GO_DEBUG_TEMPLATE) {
+			names = append(names, templates.GO_DEBUG_TEMPLATE)
+		}
+		// NOTE: java functions built with gradle have their own builder stage
+		if info.Language == types.Java && !slices.Contains(names, templates.GRADLE_CONTAINER_TEMPLATE) {
+			names = append(names, templates.GRADLE_CONTAINER_TEMPLATE)
+		}
+	}
+
+	return names, nil
+}
+
+// Copies the embedded templates into the directory, skipping templates ejected before
+func EjectTemplates(dir string, names []string) error {
+	for _, name := range names {
+		path, err := templates.EjectTemplate(dir, name)
+		if errors.Is(err, fs.ErrExist) {
+			fmt.Printf("%s already exists, skipping\n", path)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Ejected %s\n", path)
+	}
+
+	return nil
+}
+
+// Generated files of the project, debug files are only reported once written
+func generatedFiles() []string {
+	composeFile, found := templates.FindComposeFile(ConfigPath)
+	if !found {
+		composeFile = templates.DEFAULT_COMPOSE_FILE
+	}
+
+	files := []string{"Dockerfile", composeFile}
+	for _, debugFile := range []string{"Dockerfile.debug", DEBUG_COMPOSE_FILE} {
+		if _, err := os.Stat(filepath.Join(ConfigPath, debugFile)); err == nil {
+			files = append(files, debugFile)
+		}
+	}
+
+	return files
+}
+
+func TemplatesStatus(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "FILE\tTEMPLATE\tSTATUS")
+
+	outdated := false
+	for _, name := range generatedFiles() {
+		generated, state, err := templates.ReadGenerated(filepath.Join(ConfigPath, name))
+		if err != nil {
+			return err
+		}
+
+		version := "-"
+		if generated.Stamped() {
+			version = fmt.Sprintf("v%d", generated.Version)
+		}
+		outdated = outdated || state == templates.GENERATED_OUTDATED
+
+		fmt.Fprintf(table, "%s\t%s\t%s\n", name, version, state)
+	}
+
+	err := table.Flush()
+	if err != nil {
+		return err
+	}
+
+	if outdated {
+		fmt.Fprintf(w, "\nRun \"jeeves faas start --regenerate\" to update outdated files to template v%d\n", templates.TEMPLATE_VERSION)
+	}
+
+	return nil
+}
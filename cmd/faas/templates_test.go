@@ -0,0 +1,62 @@
+package faas
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/obscurelyme/jeeves/templates"
+)
+
+func TestEjectTemplates(t *testing.T) {
+	t.Run("should eject the Dockerfile templates of the runtimes", func(t *testing.T) {
+		names, err := ejectedTemplateNames([]string{"provided.al2023", "nodejs22.x", "nodejs20.x"})
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		expected := []string{"dockerfile.go.template", "dockerfile.go.container.template", templates.GO_DEBUG_TEMPLATE, "dockerfile.nodejs.template", "dockerfile.nodejs.container.template"}
+		if !slices.Equal(names, expected) {
+			t.Errorf("expected %v, but received %v", expected, names)
+		}
+	})
+
+	t.Run("should eject every template without runtimes", func(t *testing.T) {
+		names, err := ejectedTemplateNames(nil)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if !slices.Contains(names, "dockerfile.ruby.template") || !slices.Contains(names, templates.GO_DEBUG_TEMPLATE) {
+			t.Errorf("expected every template, but received %v", names)
+		}
+	})
+
+	t.Run("should fail for unknown runtimes", func(t *testing.T) {
+		_, err := ejectedTemplateNames([]string{"cobol1.0"})
+		if err == nil {
+			t.Errorf("expected an error for an unknown runtime")
+		}
+	})
+
+	t.Run("should keep templates ejected before", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), templates.PROJECT_TEMPLATE_DIR)
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "dockerfile.nodejs.template"), []byte("FROM edited"), 0644)
+
+		err := EjectTemplates(dir, []string{"dockerfile.nodejs.template", "dockerfile.java.template"})
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if data, _ := os.ReadFile(filepath.Join(dir, "dockerfile.nodejs.template")); string(data) != "FROM edited" {
+			t.Errorf("expected the ejected template to be kept, but received %s", data)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "dockerfile.java.template")); err != nil {
+			t.Errorf("expected the java template to be ejected, but received \"%s\"", err.Error())
+		}
+	})
+}
@@ -0,0 +1,729 @@
+package faas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	eventbridgeTypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/obscurelyme/jeeves/config"
+	"github.com/obscurelyme/jeeves/utils/tags"
+	"github.com/spf13/cobra"
+)
+
+type TriggerType string
+
+const (
+	TriggerSQS      TriggerType = "sqs"
+	TriggerKinesis  TriggerType = "kinesis"
+	TriggerDynamoDB TriggerType = "dynamodb"
+	TriggerS3       TriggerType = "s3"
+	TriggerSchedule TriggerType = "schedule"
+)
+
+// Characters statement ids of function policies do not allow
+var invalidStatementIdCharacters = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// Managed policies that allow the execution role to poll each event source
+var triggerPolicies = map[TriggerType]string{
+	TriggerSQS:      "arn:aws:iam::aws:policy/service-role/AWSLambdaSQSQueueExecutionRole",
+	TriggerKinesis:  "arn:aws:iam::aws:policy/service-role/AWSLambdaKinesisExecutionRole",
+	TriggerDynamoDB: "arn:aws:iam::aws:policy/service-role/AWSLambdaDynamoDBExecutionRole",
+}
+
+// An event source of a FaaS resource, as declared under triggers in faas.yaml
+type Trigger struct {
+	Type TriggerType `mapstructure:"type"`
+	// ARN of the queue or stream, used by sqs, kinesis and dynamodb triggers
+	Source           string        `mapstructure:"source"`
+	BatchSize        int32         `mapstructure:"batchSize"`
+	Window           time.Duration `mapstructure:"window"`
+	StartingPosition string        `mapstructure:"startingPosition"`
+	// Event filter patterns, IE: {"body": {"type": ["order"]}}
+	Filters []string `mapstructure:"filters"`
+	// Bucket name and notification settings, used by s3 triggers
+	Bucket string   `mapstructure:"bucket"`
+	Events []string `mapstructure:"events"`
+	Prefix string   `mapstructure:"prefix"`
+	Suffix string   `mapstructure:"suffix"`
+	// Rule name and schedule expression, used by schedule triggers
+	Name     string `mapstructure:"name"`
+	Schedule string `mapstructure:"schedule"`
+}
+
+// A trigger attached to a deployed function, the id is accepted by "faas trigger remove"
+type TriggerSummary struct {
+	Id     string `json:"id"`
+	Type   string `json:"type"`
+	Source string `json:"source"`
+	State  string `json:"state,omitempty"`
+}
+
+var trigger = Trigger{}
+var triggerFaasCmd = &cobra.Command{
+	Use:   "trigger",
+	Short: "Manage the triggers of a FaaS resource",
+	Long:  "Add, list and remove the event sources that invoke a FaaS resource",
+}
+
+var addTriggerCmd = &cobra.Command{
+	Use:   "add NAME",
+	Short: "Adds a trigger",
+	Long: `Adds a trigger to a FaaS resource, IE:
+
+  jeeves faas trigger add my-function --type sqs --source arn:aws:sqs:us-east-1:123456789012:my-queue --batch-size 10
+  jeeves faas trigger add my-function --type s3 --bucket my-bucket --events s3:ObjectCreated:* --suffix .jpg
+  jeeves faas trigger add my-function --type schedule --schedule "rate(5 minutes)"`,
+	Args: cobra.ExactArgs(1),
+	RunE: addTriggerCmdHandler,
+}
+
+var listTriggerCmd = &cobra.Command{
+	Use:   "list NAME",
+	Short: "Lists triggers",
+	Long:  "Lists the event source mappings, S3 notifications and schedules that invoke a FaaS resource",
+	Args:  cobra.ExactArgs(1),
+	RunE:  listTriggerCmdHandler,
+}
+
+var removeTriggerCmd = &cobra.Command{
+	Use:   "remove NAME ID",
+	Short: "Removes a trigger",
+	Long:  "Removes a trigger using the id printed by \"jeeves faas trigger list\"",
+	Args:  cobra.ExactArgs(2),
+	RunE:  removeTriggerCmdHandler,
+}
+
+func init() {
+	triggerFaasCmd.PersistentFlags().StringVar(&profile, "profile", "default", "AWS Profile to work with")
+
+	flags := addTriggerCmd.PersistentFlags()
+	flags.StringVar((*string)(&trigger.Type), "type", "", "sqs, kinesis, dynamodb, s3 or schedule (required)")
+	flags.StringVar(&trigger.Source, "source", "", "ARN of the queue or stream")
+	flags.Int32Var(&trigger.BatchSize, "batch-size", 0, "Maximum number of records per invocation")
+	flags.DurationVar(&trigger.Window, "window", 0, "Maximum time to gather records before invoking, IE: 5s")
+	flags.StringVar(&trigger.StartingPosition, "starting-position", "", "LATEST or TRIM_HORIZON for streams (default \"LATEST\")")
+	flags.StringArrayVar(&trigger.Filters, "filter", []string{}, "Event filter pattern, may be repeated")
+	flags.StringVar(&trigger.Bucket, "bucket", "", "Name of the S3 bucket")
+	flags.StringSliceVar(&trigger.Events, "events", []string{}, "S3 events (default \"s3:ObjectCreated:*\")")
+	flags.StringVar(&trigger.Prefix, "prefix", "", "Only notify for object keys with this prefix")
+	flags.StringVar(&trigger.Suffix, "suffix", "", "Only notify for object keys with this suffix")
+	flags.StringVar(&trigger.Name, "name", "", "Name of the EventBridge rule (default \"NAME-schedule\")")
+	flags.StringVar(&trigger.Schedule, "schedule", "", "Schedule expression, IE: rate(5 minutes) or cron(0 3 * * ? *)")
+
+	addForceFlag(removeTriggerCmd)
+
+	triggerFaasCmd.AddCommand(addTriggerCmd)
+	triggerFaasCmd.AddCommand(listTriggerCmd)
+	triggerFaasCmd.AddCommand(removeTriggerCmd)
+}
+
+func addTriggerCmdHandler(cmd *cobra.Command, args []string) error {
+	err := trigger.Validate()
+	if err != nil {
+		return err
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	id, err := ApplyTrigger(cfg, args[0], &trigger)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Trigger %s added to %s\n", id, args[0])
+	return nil
+}
+
+func listTriggerCmdHandler(cmd *cobra.Command, args []string) error {
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	triggers, err := ListTriggers(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, trigger := range triggers {
+		fmt.Printf("---\nId: %s\nType: %s\nSource: %s\n", trigger.Id, trigger.Type, trigger.Source)
+		if trigger.State != "" {
+			fmt.Printf("State: %s\n", trigger.State)
+		}
+	}
+	if len(triggers) > 0 {
+		fmt.Println("---")
+	}
+
+	return nil
+}
+
+func removeTriggerCmdHandler(cmd *cobra.Command, args []string) error {
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	err = requireManaged(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	err = RemoveTrigger(cfg, args[0], args[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Trigger %s removed from %s\n", args[1], args[0])
+	return nil
+}
+
+// Checks that the trigger has the settings its type requires and fills in defaults
+func (t *Trigger) Validate() error {
+	switch t.Type {
+	case TriggerSQS:
+		if t.Source == "" {
+			return errors.New("sqs triggers require the queue ARN, --source [ARN]")
+		}
+	case TriggerKinesis, TriggerDynamoDB:
+		if t.Source == "" {
+			return fmt.Errorf("%s triggers require the stream ARN, --source [ARN]", t.Type)
+		}
+		if t.StartingPosition == "" {
+			t.StartingPosition = string(lambdaTypes.EventSourcePositionLatest)
+		}
+	case TriggerS3:
+		if t.Bucket == "" {
+			return errors.New("s3 triggers require a bucket, --bucket [BUCKET]")
+		}
+		if len(t.Events) == 0 {
+			t.Events = []string{string(s3Types.EventS3ObjectCreated)}
+		}
+	case TriggerSchedule:
+		if t.Schedule == "" {
+			return errors.New("schedule triggers require a schedule expression, --schedule [EXPRESSION]")
+		}
+	default:
+		return fmt.Errorf("invalid trigger type \"%s\", expected one of sqs, kinesis, dynamodb, s3 or schedule", t.Type)
+	}
+
+	for _, filter := range t.Filters {
+		if !json.Valid([]byte(filter)) {
+			return fmt.Errorf("filter pattern %s is not valid JSON", filter)
+		}
+	}
+
+	return nil
+}
+
+// Creates the trigger, or updates it when the function already has one for
+// the same source, returning its id.
+func ApplyTrigger(cfg aws.Config, name string, trigger *Trigger) (string, error) {
+	lambdaClient := lambda.NewFromConfig(cfg)
+
+	function, err := lambdaClient.GetFunctionConfiguration(context.TODO(), &lambda.GetFunctionConfigurationInput{
+		FunctionName: &name,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resourceTags, err := tags.New(cfg, name)
+	if err != nil {
+		return "", err
+	}
+
+	switch trigger.Type {
+	case TriggerS3:
+		return applyS3Trigger(cfg, function, trigger)
+	case TriggerSchedule:
+		return applyScheduleTrigger(cfg, function, trigger, resourceTags)
+	}
+
+	return applyEventSourceMapping(cfg, function, trigger, resourceTags)
+}
+
+func applyEventSourceMapping(cfg aws.Config, function *lambda.GetFunctionConfigurationOutput, trigger *Trigger, resourceTags map[string]string) (string, error) {
+	lambdaClient := lambda.NewFromConfig(cfg)
+
+	err := attachTriggerPolicy(cfg, aws.ToString(function.Role), trigger.Type)
+	if err != nil {
+		return "", err
+	}
+
+	var batchSize, window *int32
+	if trigger.BatchSize > 0 {
+		batchSize = &trigger.BatchSize
+	}
+	if trigger.Window > 0 {
+		window = aws.Int32(int32(trigger.Window.Seconds()))
+	}
+
+	var filterCriteria *lambdaTypes.FilterCriteria
+	if len(trigger.Filters) > 0 {
+		filterCriteria = &lambdaTypes.FilterCriteria{}
+		for _, filter := range trigger.Filters {
+			filterCriteria.Filters = append(filterCriteria.Filters, lambdaTypes.Filter{Pattern: aws.String(filter)})
+		}
+	}
+
+	mappings, err := lambdaClient.ListEventSourceMappings(context.TODO(), &lambda.ListEventSourceMappingsInput{
+		FunctionName:   function.FunctionName,
+		EventSourceArn: &trigger.Source,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(mappings.EventSourceMappings) > 0 {
+		uuid := mappings.EventSourceMappings[0].UUID
+		_, err = lambdaClient.UpdateEventSourceMapping(context.TODO(), &lambda.UpdateEventSourceMappingInput{
+			UUID:                           uuid,
+			FunctionName:                   function.FunctionName,
+			BatchSize:                      batchSize,
+			MaximumBatchingWindowInSeconds: window,
+			FilterCriteria:                 filterCriteria,
+		})
+		return fmt.Sprintf("esm:%s", aws.ToString(uuid)), err
+	}
+
+	// NOTE: the role policy can take a few seconds to propagate before Lambda may poll the source
+	var output *lambda.CreateEventSourceMappingOutput
+	for retry := 6; retry > -1; retry-- {
+		output, err = lambdaClient.CreateEventSourceMapping(context.TODO(), &lambda.CreateEventSourceMappingInput{
+			FunctionName:                   function.FunctionName,
+			EventSourceArn:                 &trigger.Source,
+			BatchSize:                      batchSize,
+			MaximumBatchingWindowInSeconds: window,
+			FilterCriteria:                 filterCriteria,
+			StartingPosition:               lambdaTypes.EventSourcePosition(trigger.StartingPosition),
+			Tags:                           resourceTags,
+		})
+
+		var invalid *lambdaTypes.InvalidParameterValueException
+		if err == nil || !errors.As(err, &invalid) {
+			break
+		}
+		time.Sleep(5 * time.Second)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("esm:%s", aws.ToString(output.UUID)), nil
+}
+
+func applyS3Trigger(cfg aws.Config, function *lambda.GetFunctionConfigurationOutput, trigger *Trigger) (string, error) {
+	s3Client := s3.NewFromConfig(cfg)
+
+	err := addInvokePermission(cfg, function, invokeStatementId(TriggerS3, trigger.Bucket), "s3.amazonaws.com", fmt.Sprintf("arn:aws:s3:::%s", trigger.Bucket))
+	if err != nil {
+		return "", err
+	}
+
+	notifications, err := s3Client.GetBucketNotificationConfiguration(context.TODO(), &s3.GetBucketNotificationConfigurationInput{
+		Bucket: &trigger.Bucket,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	id := s3NotificationId(aws.ToString(function.FunctionName))
+	configurations := slices.DeleteFunc(notifications.LambdaFunctionConfigurations, func(c s3Types.LambdaFunctionConfiguration) bool {
+		return aws.ToString(c.Id) == id
+	})
+
+	events := []s3Types.Event{}
+	for _, event := range trigger.Events {
+		events = append(events, s3Types.Event(event))
+	}
+
+	configuration := s3Types.LambdaFunctionConfiguration{
+		Id:                &id,
+		LambdaFunctionArn: function.FunctionArn,
+		Events:            events,
+	}
+	rules := []s3Types.FilterRule{}
+	if trigger.Prefix != "" {
+		rules = append(rules, s3Types.FilterRule{Name: s3Types.FilterRuleNamePrefix, Value: &trigger.Prefix})
+	}
+	if trigger.Suffix != "" {
+		rules = append(rules, s3Types.FilterRule{Name: s3Types.FilterRuleNameSuffix, Value: &trigger.Suffix})
+	}
+	if len(rules) > 0 {
+		configuration.Filter = &s3Types.NotificationConfigurationFilter{
+			Key: &s3Types.S3KeyFilter{FilterRules: rules},
+		}
+	}
+
+	_, err = s3Client.PutBucketNotificationConfiguration(context.TODO(), &s3.PutBucketNotificationConfigurationInput{
+		Bucket: &trigger.Bucket,
+		NotificationConfiguration: &s3Types.NotificationConfiguration{
+			LambdaFunctionConfigurations: append(configurations, configuration),
+			QueueConfigurations:          notifications.QueueConfigurations,
+			TopicConfigurations:          notifications.TopicConfigurations,
+			EventBridgeConfiguration:     notifications.EventBridgeConfiguration,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("s3:%s", trigger.Bucket), nil
+}
+
+func applyScheduleTrigger(cfg aws.Config, function *lambda.GetFunctionConfigurationOutput, trigger *Trigger, resourceTags map[string]string) (string, error) {
+	eventsClient := eventbridge.NewFromConfig(cfg)
+
+	ruleName := trigger.Name
+	if ruleName == "" {
+		ruleName = fmt.Sprintf("%s-schedule", aws.ToString(function.FunctionName))
+	}
+
+	rule, err := eventsClient.PutRule(context.TODO(), &eventbridge.PutRuleInput{
+		Name:               &ruleName,
+		ScheduleExpression: &trigger.Schedule,
+		State:              eventbridgeTypes.RuleStateEnabled,
+		Tags:               tags.ToEventBridge(resourceTags),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = addInvokePermission(cfg, function, invokeStatementId(TriggerSchedule, ruleName), "events.amazonaws.com", aws.ToString(rule.RuleArn))
+	if err != nil {
+		return "", err
+	}
+
+	_, err = eventsClient.PutTargets(context.TODO(), &eventbridge.PutTargetsInput{
+		Rule: &ruleName,
+		Targets: []eventbridgeTypes.Target{{
+			Id:  aws.String(scheduleTargetId(aws.ToString(function.FunctionName))),
+			Arn: function.FunctionArn,
+		}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("schedule:%s", ruleName), nil
+}
+
+// Lists the event source mappings, S3 notifications and schedules of the function
+func ListTriggers(cfg aws.Config, name string) ([]TriggerSummary, error) {
+	lambdaClient := lambda.NewFromConfig(cfg)
+	eventsClient := eventbridge.NewFromConfig(cfg)
+	triggers := []TriggerSummary{}
+
+	function, err := lambdaClient.GetFunctionConfiguration(context.TODO(), &lambda.GetFunctionConfigurationInput{
+		FunctionName: &name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := lambda.NewListEventSourceMappingsPaginator(lambdaClient, &lambda.ListEventSourceMappingsInput{
+		FunctionName: &name,
+	})
+	for mappings.HasMorePages() {
+		page, err := mappings.NextPage(context.TODO())
+		if err != nil {
+			return nil, err
+		}
+		for _, mapping := range page.EventSourceMappings {
+			triggers = append(triggers, TriggerSummary{
+				Id:     fmt.Sprintf("esm:%s", aws.ToString(mapping.UUID)),
+				Type:   eventSourceType(aws.ToString(mapping.EventSourceArn)),
+				Source: aws.ToString(mapping.EventSourceArn),
+				State:  aws.ToString(mapping.State),
+			})
+		}
+	}
+
+	// NOTE: S3 notifications live on the bucket, the function policy tells us which buckets to look at
+	policy, err := lambdaClient.GetPolicy(context.TODO(), &lambda.GetPolicyInput{FunctionName: &name})
+	if err != nil && !isResourceNotFound(err) {
+		return nil, err
+	}
+	if policy != nil {
+		for _, bucket := range policyS3Buckets(aws.ToString(policy.Policy)) {
+			triggers = append(triggers, TriggerSummary{
+				Id:     fmt.Sprintf("s3:%s", bucket),
+				Type:   string(TriggerS3),
+				Source: fmt.Sprintf("arn:aws:s3:::%s", bucket),
+			})
+		}
+	}
+
+	var nextToken *string
+	for {
+		page, err := eventsClient.ListRuleNamesByTarget(context.TODO(), &eventbridge.ListRuleNamesByTargetInput{
+			TargetArn: function.FunctionArn,
+			NextToken: nextToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range page.RuleNames {
+			triggers = append(triggers, TriggerSummary{
+				Id:     fmt.Sprintf("schedule:%s", rule),
+				Type:   string(TriggerSchedule),
+				Source: rule,
+			})
+		}
+
+		nextToken = page.NextToken
+		if nextToken == nil {
+			break
+		}
+	}
+
+	return triggers, nil
+}
+
+// Removes a trigger by the id printed by ListTriggers, IE: esm:UUID, s3:BUCKET or schedule:RULE
+func RemoveTrigger(cfg aws.Config, name string, id string) error {
+	kind, value, found := strings.Cut(id, ":")
+	if !found || value == "" {
+		return fmt.Errorf("invalid trigger id \"%s\", expected esm:UUID, s3:BUCKET or schedule:RULE", id)
+	}
+
+	lambdaClient := lambda.NewFromConfig(cfg)
+
+	switch kind {
+	case "esm":
+		function, err := lambdaClient.GetFunctionConfiguration(context.TODO(), &lambda.GetFunctionConfigurationInput{
+			FunctionName: &name,
+		})
+		if err != nil {
+			return err
+		}
+		mapping, err := lambdaClient.GetEventSourceMapping(context.TODO(), &lambda.GetEventSourceMappingInput{
+			UUID: &value,
+		})
+		if err != nil {
+			return err
+		}
+		if !invokesFunction(aws.ToString(mapping.FunctionArn), aws.ToString(function.FunctionArn)) {
+			return fmt.Errorf("event source mapping %s does not invoke %s", value, name)
+		}
+
+		_, err = lambdaClient.DeleteEventSourceMapping(context.TODO(), &lambda.DeleteEventSourceMappingInput{
+			UUID: &value,
+		})
+		return err
+	case string(TriggerS3):
+		s3Client := s3.NewFromConfig(cfg)
+		notifications, err := s3Client.GetBucketNotificationConfiguration(context.TODO(), &s3.GetBucketNotificationConfigurationInput{
+			Bucket: &value,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = s3Client.PutBucketNotificationConfiguration(context.TODO(), &s3.PutBucketNotificationConfigurationInput{
+			Bucket: &value,
+			NotificationConfiguration: &s3Types.NotificationConfiguration{
+				LambdaFunctionConfigurations: slices.DeleteFunc(notifications.LambdaFunctionConfigurations, func(c s3Types.LambdaFunctionConfiguration) bool {
+					return aws.ToString(c.Id) == s3NotificationId(name)
+				}),
+				QueueConfigurations:      notifications.QueueConfigurations,
+				TopicConfigurations:      notifications.TopicConfigurations,
+				EventBridgeConfiguration: notifications.EventBridgeConfiguration,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		return removeInvokePermission(lambdaClient, name, invokeStatementId(TriggerS3, value))
+	case string(TriggerSchedule):
+		eventsClient := eventbridge.NewFromConfig(cfg)
+		_, err := eventsClient.RemoveTargets(context.TODO(), &eventbridge.RemoveTargetsInput{
+			Rule: &value,
+			Ids:  []string{scheduleTargetId(name)},
+		})
+		if err != nil {
+			return err
+		}
+
+		targets, err := eventsClient.ListTargetsByRule(context.TODO(), &eventbridge.ListTargetsByRuleInput{
+			Rule: &value,
+		})
+		if err != nil {
+			return err
+		}
+
+		// NOTE: only delete the rule once nothing else is targeted by it
+		if len(targets.Targets) == 0 {
+			_, err = eventsClient.DeleteRule(context.TODO(), &eventbridge.DeleteRuleInput{
+				Name: &value,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return removeInvokePermission(lambdaClient, name, invokeStatementId(TriggerSchedule, value))
+	}
+
+	return fmt.Errorf("invalid trigger id \"%s\", expected esm:UUID, s3:BUCKET or schedule:RULE", id)
+}
+
+// Removes every trigger ListTriggers finds, used before deleting the function
+func RemoveTriggers(cfg aws.Config, name string) error {
+	triggers, err := ListTriggers(cfg, name)
+	if err != nil {
+		return err
+	}
+
+	for _, trigger := range triggers {
+		err = RemoveTrigger(cfg, name, trigger.Id)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Trigger %s removed from %s\n", trigger.Id, name)
+	}
+
+	return nil
+}
+
+func addInvokePermission(cfg aws.Config, function *lambda.GetFunctionConfigurationOutput, statementId string, principal string, sourceArn string) error {
+	lambdaClient := lambda.NewFromConfig(cfg)
+
+	_, err := lambdaClient.AddPermission(context.TODO(), &lambda.AddPermissionInput{
+		FunctionName:  function.FunctionName,
+		StatementId:   &statementId,
+		Action:        aws.String("lambda:InvokeFunction"),
+		Principal:     &principal,
+		SourceArn:     &sourceArn,
+		SourceAccount: aws.String(accountFromArn(aws.ToString(function.FunctionArn))),
+	})
+
+	var conflict *lambdaTypes.ResourceConflictException
+	if errors.As(err, &conflict) {
+		// NOTE: the permission already exists
+		return nil
+	}
+
+	return err
+}
+
+func removeInvokePermission(client *lambda.Client, name string, statementId string) error {
+	_, err := client.RemovePermission(context.TODO(), &lambda.RemovePermissionInput{
+		FunctionName: &name,
+		StatementId:  &statementId,
+	})
+	if err != nil && !isResourceNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+func attachTriggerPolicy(cfg aws.Config, roleArn string, triggerType TriggerType) error {
+	policyArn, ok := triggerPolicies[triggerType]
+	if !ok {
+		return nil
+	}
+
+	iamClient := iam.NewFromConfig(cfg)
+	_, err := iamClient.AttachRolePolicy(context.TODO(), &iam.AttachRolePolicyInput{
+		PolicyArn: &policyArn,
+		RoleName:  aws.String(roleNameFromArn(roleArn)),
+	})
+
+	return err
+}
+
+// Id of the permission statement allowing the source to invoke the function, statement ids
+// only allow letters, digits, - and _, IE: my.bucket -> jeeves-s3-my_bucket
+func invokeStatementId(triggerType TriggerType, name string) string {
+	return fmt.Sprintf("jeeves-%s-%s", triggerType, invalidStatementIdCharacters.ReplaceAllString(name, "_"))
+}
+
+func s3NotificationId(functionName string) string {
+	return fmt.Sprintf("jeeves-%s", functionName)
+}
+
+func scheduleTargetId(functionName string) string {
+	return fmt.Sprintf("jeeves-%s", functionName)
+}
+
+// Returns the account id of an ARN, IE: arn:aws:lambda:us-east-1:123456789012:function:name -> 123456789012
+func accountFromArn(arn string) string {
+	parts := strings.Split(arn, ":")
+	if len(parts) < 5 {
+		return ""
+	}
+
+	return parts[4]
+}
+
+// Whether the function ARN of an event source mapping is the function, or a version or
+// alias of it, IE: arn:aws:lambda:us-east-1:123456789012:function:name:live
+func invokesFunction(mappingArn string, functionArn string) bool {
+	return mappingArn == functionArn || strings.HasPrefix(mappingArn, functionArn+":")
+}
+
+func eventSourceType(arn string) string {
+	switch {
+	case strings.HasPrefix(arn, "arn:aws:sqs:"):
+		return string(TriggerSQS)
+	case strings.HasPrefix(arn, "arn:aws:kinesis:"):
+		return string(TriggerKinesis)
+	case strings.HasPrefix(arn, "arn:aws:dynamodb:"):
+		return string(TriggerDynamoDB)
+	}
+
+	return "event-source-mapping"
+}
+
+// Finds the buckets allowed to invoke the function by the statements jeeves adds for s3 triggers
+func policyS3Buckets(policy string) []string {
+	var document struct {
+		Statement []struct {
+			Sid       string `json:"Sid"`
+			Condition struct {
+				ArnLike map[string]string `json:"ArnLike"`
+			} `json:"Condition"`
+		} `json:"Statement"`
+	}
+
+	buckets := []string{}
+	if err := json.Unmarshal([]byte(policy), &document); err != nil {
+		return buckets
+	}
+
+	for _, statement := range document.Statement {
+		if !strings.HasPrefix(statement.Sid, invokeStatementId(TriggerS3, "")) {
+			continue
+		}
+
+		// NOTE: the statement id does not hold the bucket as is, IE: dots are replaced
+		if bucket, found := strings.CutPrefix(statement.Condition.ArnLike["AWS:SourceArn"], "arn:aws:s3:::"); found {
+			buckets = append(buckets, bucket)
+		}
+	}
+
+	return buckets
+}
@@ -0,0 +1,139 @@
+package faas
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+const triggersYaml = `function:
+  name: my-function
+  runtime: nodejs20.x
+  handler: dist/index.handler
+triggers:
+  - type: sqs
+    source: arn:aws:sqs:us-east-1:123456789012:my-queue
+    batchSize: 10
+    window: 5s
+    filters:
+      - '{"body": {"type": ["order"]}}'
+  - type: dynamodb
+    source: arn:aws:dynamodb:us-east-1:123456789012:table/my-table/stream/2024-01-01T00:00:00.000
+  - type: s3
+    bucket: my-bucket
+    suffix: .jpg
+  - type: schedule
+    schedule: rate(5 minutes)`
+
+func TestReadTriggers(t *testing.T) {
+	tmpDir := t.TempDir()
+	ConfigPath = tmpDir
+
+	t.Run("should read the triggers of faas.yaml and fill in defaults", func(t *testing.T) {
+		setup(tmpDir, triggersYaml)
+
+		faasConfig, err := ReadLambdaConfig()
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		triggers, err := ReadTriggers(faasConfig)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if len(triggers) != 4 {
+			t.Errorf("expected 4 triggers, but received %d", len(triggers))
+			return
+		}
+
+		sqs := triggers[0]
+		if sqs.Type != TriggerSQS || sqs.BatchSize != 10 || sqs.Window != 5*time.Second || len(sqs.Filters) != 1 {
+			t.Errorf("unexpected sqs trigger %+v", sqs)
+		}
+
+		if triggers[1].StartingPosition != "LATEST" {
+			t.Errorf("expected dynamodb trigger to start at LATEST, but received \"%s\"", triggers[1].StartingPosition)
+		}
+
+		if !slices.Equal(triggers[2].Events, []string{"s3:ObjectCreated:*"}) {
+			t.Errorf("expected s3 trigger to default to s3:ObjectCreated:*, but received %v", triggers[2].Events)
+		}
+
+		if triggers[3].Schedule != "rate(5 minutes)" {
+			t.Errorf("expected schedule \"rate(5 minutes)\", but received \"%s\"", triggers[3].Schedule)
+		}
+	})
+
+	t.Run("should reject triggers missing required settings", func(t *testing.T) {
+		setup(tmpDir, `triggers:
+  - type: kinesis`)
+
+		faasConfig, err := ReadLambdaConfig()
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		_, err = ReadTriggers(faasConfig)
+		if err == nil {
+			t.Errorf("expected an error for a kinesis trigger without a source")
+		}
+	})
+}
+
+func TestTriggerValidate(t *testing.T) {
+	t.Run("should reject unknown trigger types", func(t *testing.T) {
+		err := (&Trigger{Type: "sns"}).Validate()
+		if err == nil {
+			t.Errorf("expected an error for an unknown trigger type")
+		}
+	})
+
+	t.Run("should reject filters which are not JSON", func(t *testing.T) {
+		err := (&Trigger{Type: TriggerSQS, Source: "arn:aws:sqs:us-east-1:123456789012:q", Filters: []string{"{body"}}).Validate()
+		if err == nil {
+			t.Errorf("expected an error for an invalid filter pattern")
+		}
+	})
+}
+
+func TestPolicyS3Buckets(t *testing.T) {
+	t.Run("should find the buckets of jeeves s3 permission statements", func(t *testing.T) {
+		policy := `{"Version":"2012-10-17","Statement":[
+			{"Sid":"jeeves-s3-my-bucket","Effect":"Allow","Condition":{"ArnLike":{"AWS:SourceArn":"arn:aws:s3:::my-bucket"}}},
+			{"Sid":"jeeves-function-url-public","Effect":"Allow"},
+			{"Sid":"jeeves-s3-assets_example_com","Effect":"Allow","Condition":{"ArnLike":{"AWS:SourceArn":"arn:aws:s3:::assets.example.com"}}}]}`
+
+		buckets := policyS3Buckets(policy)
+		if !slices.Equal(buckets, []string{"my-bucket", "assets.example.com"}) {
+			t.Errorf("expected [my-bucket assets.example.com], but received %v", buckets)
+		}
+	})
+
+	t.Run("should only use valid characters in statement ids", func(t *testing.T) {
+		id := invokeStatementId(TriggerS3, "assets.example.com")
+		if id != "jeeves-s3-assets_example_com" {
+			t.Errorf("expected jeeves-s3-assets_example_com, but received \"%s\"", id)
+		}
+	})
+
+	t.Run("should match event source mappings of the function and its aliases only", func(t *testing.T) {
+		function := "arn:aws:lambda:us-east-1:123456789012:function:my-function"
+		if !invokesFunction(function, function) || !invokesFunction(function+":live", function) {
+			t.Errorf("expected the mappings of the function and its alias to match")
+		}
+		if invokesFunction(function+"-other", function) || invokesFunction("arn:aws:lambda:us-east-1:123456789012:function:other", function) {
+			t.Errorf("expected the mappings of other functions not to match")
+		}
+	})
+
+	t.Run("should read the account of an ARN", func(t *testing.T) {
+		account := accountFromArn("arn:aws:lambda:us-east-1:123456789012:function:my-function")
+		if account != "123456789012" {
+			t.Errorf("expected 123456789012, but received \"%s\"", account)
+		}
+	})
+}
@@ -0,0 +1,423 @@
+package faas
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/obscurelyme/jeeves/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Statement id of the resource-based permission allowing public access to a function url
+const FUNCTION_URL_PERMISSION_SID string = "jeeves-function-url-public"
+
+var urlAuthType string
+var urlQualifier string
+var urlCors = lambdaTypes.Cors{}
+var urlCorsMaxAge int32
+var urlCorsCredentials bool
+var urlMethod string
+var urlPath string
+var urlHeaders []string
+var urlFaasCmd = &cobra.Command{
+	Use:   "url",
+	Short: "Manage the function url of a FaaS resource",
+	Long:  "Enable, disable, show and call the HTTPS endpoint of a FaaS resource",
+}
+
+var enableUrlCmd = &cobra.Command{
+	Use:   "enable NAME",
+	Short: "Creates or updates a function url",
+	Long: `Creates or updates the function url of a FaaS resource.
+The auth type and CORS settings are read from the url section of faas.yaml, flags take precedence:
+
+function:
+  url:
+    authType: NONE
+    cors:
+      allowOrigins: ["*"]
+      allowMethods: ["GET", "POST"]`,
+	Args: cobra.ExactArgs(1),
+	RunE: enableUrlCmdHandler,
+}
+
+var disableUrlCmd = &cobra.Command{
+	Use:   "disable NAME",
+	Short: "Deletes a function url",
+	Long:  "Deletes the function url of a FaaS resource and its public access permission",
+	Args:  cobra.ExactArgs(1),
+	RunE:  disableUrlCmdHandler,
+}
+
+var showUrlCmd = &cobra.Command{
+	Use:   "show NAME",
+	Short: "Prints a function url",
+	Long:  "Prints the function url of a FaaS resource and its auth type",
+	Args:  cobra.ExactArgs(1),
+	RunE:  showUrlCmdHandler,
+}
+
+var callUrlCmd = &cobra.Command{
+	Use:   "call NAME",
+	Short: "Sends a request to a function url",
+	Long: `Sends a request to the function url of a FaaS resource.
+Requests to AWS_IAM urls are signed with the credentials of the current profile.`,
+	Args: cobra.ExactArgs(1),
+	RunE: callUrlCmdHandler,
+}
+
+func init() {
+	urlFaasCmd.PersistentFlags().StringVar(&profile, "profile", "default", "AWS Profile to work with")
+	urlFaasCmd.PersistentFlags().StringVarP(&urlQualifier, "qualifier", "q", "", "Alias the function url belongs to")
+
+	enableUrlCmd.PersistentFlags().StringVar(&urlAuthType, "auth-type", "", "NONE for a public url or AWS_IAM (default \"NONE\")")
+	enableUrlCmd.PersistentFlags().StringSliceVar(&urlCors.AllowOrigins, "cors-origin", []string{}, "Allowed CORS origins")
+	enableUrlCmd.PersistentFlags().StringSliceVar(&urlCors.AllowMethods, "cors-method", []string{}, "Allowed CORS methods")
+	enableUrlCmd.PersistentFlags().StringSliceVar(&urlCors.AllowHeaders, "cors-header", []string{}, "Allowed CORS request headers")
+	enableUrlCmd.PersistentFlags().StringSliceVar(&urlCors.ExposeHeaders, "cors-expose-header", []string{}, "CORS response headers exposed to the browser")
+	enableUrlCmd.PersistentFlags().Int32Var(&urlCorsMaxAge, "cors-max-age", 0, "Seconds browsers may cache preflight responses")
+	enableUrlCmd.PersistentFlags().BoolVar(&urlCorsCredentials, "cors-credentials", false, "Allow cookies and credentials in CORS requests")
+
+	callUrlCmd.PersistentFlags().StringVarP(&urlMethod, "method", "X", http.MethodGet, "HTTP method")
+	callUrlCmd.PersistentFlags().StringVar(&urlPath, "path", "/", "Path and query string to request")
+	callUrlCmd.PersistentFlags().StringArrayVarP(&urlHeaders, "header", "H", []string{}, "Request header, IE: -H \"Content-Type: application/json\"")
+	callUrlCmd.PersistentFlags().StringVarP(&invokeData, "data", "d", "", "Request body")
+	callUrlCmd.PersistentFlags().StringVarP(&invokePayloadFile, "payload", "p", "", "File containing the request body, \"-\" reads from stdin")
+
+	addForceFlag(disableUrlCmd)
+
+	urlFaasCmd.AddCommand(enableUrlCmd)
+	urlFaasCmd.AddCommand(disableUrlCmd)
+	urlFaasCmd.AddCommand(showUrlCmd)
+	urlFaasCmd.AddCommand(callUrlCmd)
+}
+
+type FunctionUrlSettings struct {
+	AuthType lambdaTypes.FunctionUrlAuthType
+	Cors     *lambdaTypes.Cors
+}
+
+func enableUrlCmdHandler(cmd *cobra.Command, args []string) error {
+	faasConfig, err := ReadOptionalLambdaConfig()
+	if err != nil {
+		return err
+	}
+
+	settings, err := functionUrlSettings(cmd, faasConfig)
+	if err != nil {
+		return err
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	functionUrl, err := EnableFunctionUrl(cfg, args[0], settings)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Function url for %s (%s): %s\n", args[0], settings.AuthType, functionUrl)
+	return nil
+}
+
+// Merges the url settings of faas.yaml with the flags the user changed
+func functionUrlSettings(cmd *cobra.Command, faasConfig *viper.Viper) (*FunctionUrlSettings, error) {
+	flags := cmd.PersistentFlags()
+	settings := &FunctionUrlSettings{
+		AuthType: lambdaTypes.FunctionUrlAuthTypeNone,
+		Cors: &lambdaTypes.Cors{
+			AllowOrigins:  faasConfig.GetStringSlice("function.url.cors.allowOrigins"),
+			AllowMethods:  faasConfig.GetStringSlice("function.url.cors.allowMethods"),
+			AllowHeaders:  faasConfig.GetStringSlice("function.url.cors.allowHeaders"),
+			ExposeHeaders: faasConfig.GetStringSlice("function.url.cors.exposeHeaders"),
+		},
+	}
+
+	authType := faasConfig.GetString("function.url.authType")
+	if flags.Changed("auth-type") {
+		authType = urlAuthType
+	}
+	if authType != "" {
+		settings.AuthType = lambdaTypes.FunctionUrlAuthType(strings.ToUpper(authType))
+	}
+	if settings.AuthType != lambdaTypes.FunctionUrlAuthTypeNone && settings.AuthType != lambdaTypes.FunctionUrlAuthTypeAwsIam {
+		return nil, fmt.Errorf("invalid auth type \"%s\", expected NONE or AWS_IAM", authType)
+	}
+
+	if flags.Changed("cors-origin") {
+		settings.Cors.AllowOrigins = urlCors.AllowOrigins
+	}
+	if flags.Changed("cors-method") {
+		settings.Cors.AllowMethods = urlCors.AllowMethods
+	}
+	if flags.Changed("cors-header") {
+		settings.Cors.AllowHeaders = urlCors.AllowHeaders
+	}
+	if flags.Changed("cors-expose-header") {
+		settings.Cors.ExposeHeaders = urlCors.ExposeHeaders
+	}
+
+	if faasConfig.IsSet("function.url.cors.maxAge") {
+		settings.Cors.MaxAge = aws.Int32(faasConfig.GetInt32("function.url.cors.maxAge"))
+	}
+	if flags.Changed("cors-max-age") {
+		settings.Cors.MaxAge = &urlCorsMaxAge
+	}
+
+	if faasConfig.IsSet("function.url.cors.allowCredentials") {
+		settings.Cors.AllowCredentials = aws.Bool(faasConfig.GetBool("function.url.cors.allowCredentials"))
+	}
+	if flags.Changed("cors-credentials") {
+		settings.Cors.AllowCredentials = &urlCorsCredentials
+	}
+
+	return settings, nil
+}
+
+// Creates the function url, or updates it if one exists, returning the endpoint.
+// Public urls also receive the resource-based permission that allows anyone to call them.
+func EnableFunctionUrl(cfg aws.Config, name string, settings *FunctionUrlSettings) (string, error) {
+	client := lambda.NewFromConfig(cfg)
+	qualifier := optionalString(urlQualifier)
+	var functionUrl *string
+
+	_, err := client.GetFunctionUrlConfig(context.TODO(), &lambda.GetFunctionUrlConfigInput{
+		FunctionName: &name,
+		Qualifier:    qualifier,
+	})
+	if err != nil && !isResourceNotFound(err) {
+		return "", err
+	}
+
+	if err == nil {
+		output, err := client.UpdateFunctionUrlConfig(context.TODO(), &lambda.UpdateFunctionUrlConfigInput{
+			FunctionName: &name,
+			Qualifier:    qualifier,
+			AuthType:     settings.AuthType,
+			Cors:         settings.Cors,
+		})
+		if err != nil {
+			return "", err
+		}
+		functionUrl = output.FunctionUrl
+	} else {
+		output, err := client.CreateFunctionUrlConfig(context.TODO(), &lambda.CreateFunctionUrlConfigInput{
+			FunctionName: &name,
+			Qualifier:    qualifier,
+			AuthType:     settings.AuthType,
+			Cors:         settings.Cors,
+		})
+		if err != nil {
+			return "", err
+		}
+		functionUrl = output.FunctionUrl
+	}
+
+	err = removeFunctionUrlPermission(client, name)
+	if err != nil {
+		return "", err
+	}
+
+	if settings.AuthType == lambdaTypes.FunctionUrlAuthTypeNone {
+		_, err = client.AddPermission(context.TODO(), &lambda.AddPermissionInput{
+			FunctionName:        &name,
+			Qualifier:           qualifier,
+			StatementId:         aws.String(FUNCTION_URL_PERMISSION_SID),
+			Action:              aws.String("lambda:InvokeFunctionUrl"),
+			Principal:           aws.String("*"),
+			FunctionUrlAuthType: lambdaTypes.FunctionUrlAuthTypeNone,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return aws.ToString(functionUrl), nil
+}
+
+func removeFunctionUrlPermission(client *lambda.Client, name string) error {
+	_, err := client.RemovePermission(context.TODO(), &lambda.RemovePermissionInput{
+		FunctionName: &name,
+		Qualifier:    optionalString(urlQualifier),
+		StatementId:  aws.String(FUNCTION_URL_PERMISSION_SID),
+	})
+	if err != nil && !isResourceNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+func disableUrlCmdHandler(cmd *cobra.Command, args []string) error {
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	err = requireManaged(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	client := lambda.NewFromConfig(cfg)
+	_, err = client.DeleteFunctionUrlConfig(context.TODO(), &lambda.DeleteFunctionUrlConfigInput{
+		FunctionName: &args[0],
+		Qualifier:    optionalString(urlQualifier),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = removeFunctionUrlPermission(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Function url for %s was deleted\n", args[0])
+	return nil
+}
+
+func showUrlCmdHandler(cmd *cobra.Command, args []string) error {
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	client := lambda.NewFromConfig(cfg)
+	output, err := client.GetFunctionUrlConfig(context.TODO(), &lambda.GetFunctionUrlConfigInput{
+		FunctionName: &args[0],
+		Qualifier:    optionalString(urlQualifier),
+	})
+	if isResourceNotFound(err) {
+		return fmt.Errorf("%s has no function url, run \"jeeves faas url enable %s\"", args[0], args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Function URL: %s\nAuth Type: %s\n", aws.ToString(output.FunctionUrl), output.AuthType)
+	if output.Cors != nil {
+		fmt.Printf("CORS Origins: %s\nCORS Methods: %s\n", strings.Join(output.Cors.AllowOrigins, ", "), strings.Join(output.Cors.AllowMethods, ", "))
+	}
+
+	return nil
+}
+
+func callUrlCmdHandler(cmd *cobra.Command, args []string) error {
+	body, err := ReadPayload(invokeData, invokePayloadFile, os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	loader := config.AWSConfigLoader{}
+	cfg, err := loader.LoadAWSConfig(profile)
+	if err != nil {
+		return err
+	}
+
+	client := lambda.NewFromConfig(cfg)
+	output, err := client.GetFunctionUrlConfig(context.TODO(), &lambda.GetFunctionUrlConfigInput{
+		FunctionName: &args[0],
+		Qualifier:    optionalString(urlQualifier),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := newFunctionUrlRequest(aws.ToString(output.FunctionUrl), urlMethod, urlPath, urlHeaders, body)
+	if err != nil {
+		return err
+	}
+
+	if output.AuthType == lambdaTypes.FunctionUrlAuthTypeAwsIam {
+		err = signFunctionUrlRequest(context.TODO(), cfg, req, body)
+		if err != nil {
+			return err
+		}
+	}
+
+	cmd.SilenceUsage = true
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%s %s\n", res.Proto, res.Status)
+	fmt.Println(formatResponse(data))
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("request failed with status code: %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+func newFunctionUrlRequest(functionUrl string, method string, path string, headers []string, body []byte) (*http.Request, error) {
+	endpoint, err := url.Parse(functionUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	reference, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(strings.ToUpper(method), endpoint.ResolveReference(reference).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, header := range headers {
+		key, value, found := strings.Cut(header, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid header \"%s\", expected \"Name: value\"", header)
+		}
+		req.Header.Add(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+
+	return req, nil
+}
+
+// Signs the request with SigV4 for the lambda service using the credentials of the config
+func signFunctionUrlRequest(ctx context.Context, cfg aws.Config, req *http.Request, body []byte) error {
+	if cfg.Credentials == nil {
+		return errors.New("no credentials available to sign the request, please run \"jeeves login\"")
+	}
+
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(body)
+	signer := v4.NewSigner()
+
+	return signer.SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "lambda", cfg.Region, time.Now())
+}
@@ -0,0 +1,124 @@
+package faas
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+const urlYaml = `function:
+  runtime: nodejs20.x
+  handler: dist/index.js
+  url:
+    authType: AWS_IAM
+    cors:
+      allowOrigins: ["https://example.com"]
+      allowMethods: ["GET"]
+      maxAge: 300`
+
+func TestFunctionUrl(t *testing.T) {
+	tmpDir := t.TempDir()
+	ConfigPath = tmpDir
+	setup(tmpDir, urlYaml)
+
+	t.Run("should read the url settings from faas.yaml", func(t *testing.T) {
+		faasConfig, err := ReadLambdaConfig()
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		settings, err := functionUrlSettings(enableUrlCmd, faasConfig)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if settings.AuthType != lambdaTypes.FunctionUrlAuthTypeAwsIam {
+			t.Errorf("expected auth type AWS_IAM, received %s", settings.AuthType)
+		}
+
+		if len(settings.Cors.AllowOrigins) != 1 || settings.Cors.AllowOrigins[0] != "https://example.com" {
+			t.Errorf("expected the CORS origin from faas.yaml, received %v", settings.Cors.AllowOrigins)
+		}
+
+		if aws.ToInt32(settings.Cors.MaxAge) != 300 {
+			t.Errorf("expected a CORS max age of 300, received %d", aws.ToInt32(settings.Cors.MaxAge))
+		}
+	})
+
+	t.Run("should prefer flags over faas.yaml", func(t *testing.T) {
+		faasConfig, _ := ReadLambdaConfig()
+		enableUrlCmd.PersistentFlags().Set("auth-type", "none")
+		defer func() {
+			enableUrlCmd.PersistentFlags().Set("auth-type", "")
+			enableUrlCmd.PersistentFlags().Lookup("auth-type").Changed = false
+		}()
+
+		settings, err := functionUrlSettings(enableUrlCmd, faasConfig)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if settings.AuthType != lambdaTypes.FunctionUrlAuthTypeNone {
+			t.Errorf("expected auth type NONE, received %s", settings.AuthType)
+		}
+	})
+
+	t.Run("should sign requests with SigV4", func(t *testing.T) {
+		req, err := newFunctionUrlRequest("https://abc.lambda-url.us-east-1.on.aws/", "post", "/items?id=1", []string{"Content-Type: application/json"}, []byte(`{}`))
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if req.URL.String() != "https://abc.lambda-url.us-east-1.on.aws/items?id=1" {
+			t.Errorf("unexpected request url %s", req.URL.String())
+		}
+
+		cfg := aws.Config{
+			Region:      "us-east-1",
+			Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		}
+		err = signFunctionUrlRequest(context.TODO(), cfg, req, []byte(`{}`))
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if !strings.Contains(req.Header.Get("Authorization"), "/us-east-1/lambda/aws4_request") {
+			t.Errorf("expected a SigV4 authorization header, received \"%s\"", req.Header.Get("Authorization"))
+		}
+	})
+}
+
+func TestReadOptionalLambdaConfig(t *testing.T) {
+	t.Run("should read a missing faas.yaml as an empty one", func(t *testing.T) {
+		ConfigPath = t.TempDir()
+
+		faasConfig, err := ReadOptionalLambdaConfig()
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if faasConfig.GetString("function.url.authType") != "" {
+			t.Errorf("expected no settings, but received %v", faasConfig.AllSettings())
+		}
+	})
+
+	t.Run("should fail for an invalid faas.yaml", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		ConfigPath = tmpDir
+		setup(tmpDir, "function: [")
+
+		_, err := ReadOptionalLambdaConfig()
+		if err == nil {
+			t.Errorf("expected an error for an invalid faas.yaml")
+		}
+	})
+}
@@ -0,0 +1,421 @@
+package faas
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/fsnotify/fsnotify"
+	"github.com/obscurelyme/jeeves/templates"
+	"github.com/obscurelyme/jeeves/types"
+	"github.com/obscurelyme/jeeves/utils/java"
+	"github.com/spf13/viper"
+)
+
+// How long the watcher waits for changes to settle before rebuilding
+const DEFAULT_WATCH_DEBOUNCE time.Duration = 300 * time.Millisecond
+
+// Compose service running the function
+const COMPOSE_SERVICE string = templates.LAMBDA_SERVICE
+
+// Root of the function code within the Lambda base images
+const LAMBDA_TASK_ROOT string = "/var/task"
+
+// Directories which never contain sources, IE: build output or installed dependencies
+var watchIgnoredDirs = []string{".git", ".jeeves", "node_modules", "dist", "target", "vendor", ".venv", "venv", "__pycache__", ".bundle"}
+
+// A build artifact copied into the running container after a build
+type WatchSync struct {
+	// Path of the artifact relative to the project
+	Source string
+	// Path within the container
+	Target string
+}
+
+// How the sources of a runtime are watched and brought into the running container
+type WatchRule struct {
+	// Files and directories watched relative to the project, IE: src
+	Paths []string
+	// Only files with these extensions are relevant, all files when empty
+	Extensions []string
+	// Files whose changes require rebuilding the image, IE: dependency manifests
+	Rebuild []string
+	// Build step run before syncing, none when empty
+	Build string
+	// Artifacts synced into the container, the changed files themselves when empty
+	Sync []WatchSync
+	// Every change rebuilds the image, IE: when the image builds the sources itself
+	RebuildAll bool
+	// Run before rebuilding the image, IE: to vendor the changed dependencies
+	Prepare func() error
+}
+
+var watchRules = map[types.LambdaLanguage]WatchRule{
+	types.NodeJs: {
+		Paths:   []string{"src", "tsconfig.json", "package.json", "package-lock.json"},
+		Rebuild: []string{"package.json", "package-lock.json"},
+		Build:   "npm run build",
+		Sync:    []WatchSync{{Source: "dist/.", Target: LAMBDA_TASK_ROOT + "/dist"}},
+	},
+	types.Golang: {
+		Paths:      []string{"."},
+		Extensions: []string{".go", ".mod", ".sum"},
+		Rebuild:    []string{"go.mod", "go.sum"},
+		Build:      "make build",
+		Sync:       []WatchSync{{Source: "bootstrap", Target: LAMBDA_TASK_ROOT + "/bootstrap"}},
+	},
+	types.Java: {
+		Paths:   []string{"src/main", "pom.xml"},
+		Rebuild: []string{"pom.xml"},
+		Build:   "mvn -q compile",
+		Sync:    []WatchSync{{Source: "target/classes/.", Target: LAMBDA_TASK_ROOT}},
+	},
+	types.Python: {
+		Paths:      []string{"src", "pyproject.toml", "requirements.txt", "poetry.lock", "uv.lock"},
+		Extensions: []string{".py", ".toml", ".txt", ".lock"},
+		Rebuild:    []string{"pyproject.toml", "requirements.txt", "poetry.lock", "uv.lock"},
+		Sync:       []WatchSync{{Source: "src/.", Target: LAMBDA_TASK_ROOT}},
+	},
+	types.Ruby: {
+		Paths:      []string{"."},
+		Extensions: []string{".rb", "Gemfile", ".lock"},
+		Rebuild:    []string{"Gemfile", "Gemfile.lock"},
+	},
+}
+
+// What a batch of changes requires
+type WatchAction int
+
+const (
+	WATCH_IGNORE WatchAction = iota
+	WATCH_SYNC
+	WATCH_REBUILD
+)
+
+// Reads the watch rule of the runtime, function.watch.paths and function.watch.build of
+// faas.yaml override the runtime's defaults
+func ReadWatchRule(faasConfig *viper.Viper) (WatchRule, error) {
+	runtime := faasConfig.GetString("function.runtime")
+
+	info, err := types.LookupRuntime(runtime)
+	if err != nil {
+		return WatchRule{}, err
+	}
+
+	rule, ok := watchRules[info.Language]
+	if !ok {
+		return WatchRule{}, fmt.Errorf("watching the \"%s\" runtime is not supported", runtime)
+	}
+
+	if info.Language == types.Java {
+		rule, err = javaWatchRule(rule)
+		if err != nil {
+			return WatchRule{}, err
+		}
+	}
+
+	if faasConfig.IsSet("function.watch.paths") {
+		rule.Paths = faasConfig.GetStringSlice("function.watch.paths")
+		rule.Extensions = nil
+	}
+	if faasConfig.IsSet("function.watch.build") {
+		rule.Build = faasConfig.GetString("function.watch.build")
+	}
+
+	return rule, nil
+}
+
+// Watches the build file of gradle projects instead of pom.xml and syncs their classes
+func javaWatchRule(rule WatchRule) (WatchRule, error) {
+	project, err := java.DetectProject(ConfigPath)
+	if err != nil || project.Tool != java.BUILD_TOOL_GRADLE {
+		return rule, err
+	}
+
+	buildFiles := []string{project.BuildFile, "settings.gradle", "settings.gradle.kts"}
+	rule.Paths = append([]string{"src/main"}, buildFiles...)
+	rule.Rebuild = buildFiles
+	rule.Build = project.CompileCommand()
+	rule.Sync = []WatchSync{{Source: project.ClassesPath() + "/.", Target: LAMBDA_TASK_ROOT}}
+
+	return rule, nil
+}
+
+// Reports whether the changed file, relative to the project, is a source of the rule
+func (r *WatchRule) Relevant(name string) bool {
+	name = filepath.ToSlash(filepath.Clean(name))
+
+	for _, part := range strings.Split(name, "/") {
+		if slices.Contains(watchIgnoredDirs, part) {
+			return false
+		}
+	}
+
+	if slices.Contains(r.Rebuild, name) {
+		return true
+	}
+
+	watched := slices.ContainsFunc(r.Paths, func(path string) bool {
+		path = filepath.ToSlash(filepath.Clean(path))
+		return path == "." || name == path || strings.HasPrefix(name, path+"/")
+	})
+	if !watched {
+		return false
+	}
+
+	if len(r.Extensions) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(r.Extensions, func(extension string) bool {
+		return strings.HasSuffix(name, extension)
+	})
+}
+
+// Decides what a batch of changed files, relative to the project, requires
+func (r *WatchRule) Classify(changed []string) WatchAction {
+	action := WATCH_IGNORE
+
+	for _, name := range changed {
+		if !r.Relevant(name) {
+			continue
+		}
+		if r.RebuildAll || slices.Contains(r.Rebuild, filepath.ToSlash(filepath.Clean(name))) {
+			return WATCH_REBUILD
+		}
+		action = WATCH_SYNC
+	}
+
+	return action
+}
+
+// Collects changed files until none changed for the delay, IE: an editor saving several files at once
+type changeBatch struct {
+	delay   time.Duration
+	timer   *time.Timer
+	pending []string
+}
+
+func newChangeBatch(delay time.Duration) *changeBatch {
+	timer := time.NewTimer(delay)
+	timer.Stop()
+
+	return &changeBatch{delay: delay, timer: timer, pending: []string{}}
+}
+
+// Adds the changed file and restarts the delay
+func (b *changeBatch) Add(name string) {
+	if !slices.Contains(b.pending, name) {
+		b.pending = append(b.pending, name)
+	}
+	b.timer.Reset(b.delay)
+}
+
+// Fires once no file changed for the delay
+func (b *changeBatch) Ready() <-chan time.Time {
+	return b.timer.C
+}
+
+// Returns the collected files and starts a new batch
+func (b *changeBatch) Flush() []string {
+	pending := b.pending
+	b.pending = []string{}
+	return pending
+}
+
+// Runs the function with docker compose in the background and keeps the
+// container up to date with the sources until interrupted. With rebuildAll
+// every change rebuilds the image, IE: for a Dockerfile.debug building the sources.
+func watchFaaS(faasConfig *viper.Viper, architecture lambdaTypes.Architecture, rebuildAll bool) error {
+	rule, err := ReadWatchRule(faasConfig)
+	if err != nil {
+		return err
+	}
+	if rebuildAll {
+		rule.RebuildAll = true
+		rule.Build = ""
+	}
+	// NOTE: the image copies the dependencies vendored on the host
+	if runtime := faasConfig.GetString("function.runtime"); strings.HasPrefix(runtime, "python") && !rebuildAll {
+		rule.Prepare = func() error {
+			return VendorPythonDependencies(runtime, architecture)
+		}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	err = addWatchDirs(watcher, ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	watchLog("starting %s, watching %s", COMPOSE_SERVICE, strings.Join(rule.Paths, ", "))
+	err = runCompose("up", "--build", "--detach")
+	if err != nil {
+		return err
+	}
+
+	logs := exec.Command("docker", "compose", "logs", "--follow", COMPOSE_SERVICE)
+	logs.Dir = ConfigPath
+	logs.Stdout = os.Stdout
+	logs.Stderr = os.Stderr
+	err = logs.Start()
+	if err != nil {
+		return err
+	}
+	defer logs.Process.Kill()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	batch := newChangeBatch(DEFAULT_WATCH_DEBOUNCE)
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) {
+				// NOTE: fsnotify does not watch recursively, pick up new directories
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					addWatchDirs(watcher, event.Name)
+				}
+			}
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+			relative, err := filepath.Rel(ConfigPath, event.Name)
+			if err == nil && rule.Relevant(relative) {
+				batch.Add(relative)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			watchLog("watch error: %s", err.Error())
+		case <-batch.Ready():
+			reload(&rule, batch.Flush())
+		case <-interrupt:
+			watchLog("stopping %s", COMPOSE_SERVICE)
+			return runCompose("stop")
+		}
+	}
+}
+
+// Builds and brings the changes into the running container, a failed build
+// keeps the running container as is
+func reload(rule *WatchRule, changed []string) {
+	action := rule.Classify(changed)
+	if action == WATCH_IGNORE {
+		return
+	}
+
+	summary := changed[0]
+	if len(changed) > 1 {
+		summary = fmt.Sprintf("%s (+%d more)", changed[0], len(changed)-1)
+	}
+	watchLog("changed %s", summary)
+	start := time.Now()
+
+	if rule.Build != "" {
+		watchLog("building: %s", rule.Build)
+		buildCmd := exec.Command("sh", "-c", rule.Build)
+		buildCmd.Dir = ConfigPath
+		output, err := buildCmd.CombinedOutput()
+		if err != nil {
+			watchLog("build failed, keeping the running container:\n%s", output)
+			return
+		}
+	}
+
+	if action == WATCH_REBUILD {
+		if rule.Prepare != nil {
+			err := rule.Prepare()
+			if err != nil {
+				watchLog("preparing the rebuild failed, keeping the running container: %s", err.Error())
+				return
+			}
+		}
+		watchLog("dependencies changed, rebuilding the image")
+		err := runCompose("up", "--build", "--detach")
+		if err != nil {
+			watchLog("rebuild failed: %s", err.Error())
+			return
+		}
+		watchLog("rebuilt in %s", time.Since(start).Round(time.Millisecond))
+		return
+	}
+
+	syncs := rule.Sync
+	if len(syncs) == 0 {
+		for _, name := range changed {
+			syncs = append(syncs, WatchSync{Source: name, Target: LAMBDA_TASK_ROOT + "/" + filepath.ToSlash(name)})
+		}
+	}
+
+	for _, sync := range syncs {
+		if _, err := os.Stat(filepath.Join(ConfigPath, sync.Source)); errors.Is(err, fs.ErrNotExist) {
+			// NOTE: deleted files are picked up by the next rebuild
+			continue
+		}
+		err := runCompose("cp", sync.Source, fmt.Sprintf("%s:%s", COMPOSE_SERVICE, sync.Target))
+		if err != nil {
+			watchLog("sync of %s failed: %s", sync.Source, err.Error())
+			return
+		}
+	}
+
+	// NOTE: the runtime keeps the function loaded between invocations
+	err := runCompose("restart", COMPOSE_SERVICE)
+	if err != nil {
+		watchLog("restart failed: %s", err.Error())
+		return
+	}
+	watchLog("reloaded in %s", time.Since(start).Round(time.Millisecond))
+}
+
+// Watches the directory and every directory below it, skipping ignored directories
+func addWatchDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && slices.Contains(watchIgnoredDirs, d.Name()) {
+			return filepath.SkipDir
+		}
+
+		return watcher.Add(path)
+	})
+}
+
+func runCompose(args ...string) error {
+	composeCmd := exec.Command("docker", append([]string{"compose"}, args...)...)
+	composeCmd.Dir = ConfigPath
+
+	output, err := composeCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("docker compose %s failed: %s", args[0], strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+func watchLog(format string, args ...any) {
+	fmt.Printf("[watch %s] %s\n", time.Now().Format(time.TimeOnly), fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,126 @@
+package faas
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/obscurelyme/jeeves/types"
+)
+
+func TestWatchRule(t *testing.T) {
+	nodeRule := watchRules[types.NodeJs]
+	goRule := watchRules[types.Golang]
+
+	t.Run("should only consider the sources of the runtime", func(t *testing.T) {
+		cases := map[string]bool{
+			"src/index.ts":                 true,
+			"src/handlers/orders.ts":       true,
+			"package.json":                 true,
+			"dist/index.js":                false,
+			"node_modules/lodash/index.js": false,
+			"README.md":                    false,
+		}
+
+		for name, expected := range cases {
+			if relevant := nodeRule.Relevant(name); relevant != expected {
+				t.Errorf("expected %s to be relevant: %t, but received %t", name, expected, relevant)
+			}
+		}
+	})
+
+	t.Run("should filter by extension", func(t *testing.T) {
+		if !goRule.Relevant("internal/orders.go") {
+			t.Errorf("expected go sources to be relevant")
+		}
+		if goRule.Relevant("bootstrap") {
+			t.Errorf("expected the build output to be ignored")
+		}
+	})
+
+	t.Run("should rebuild the image when dependencies change", func(t *testing.T) {
+		if action := nodeRule.Classify([]string{"src/index.ts", "package.json"}); action != WATCH_REBUILD {
+			t.Errorf("expected a rebuild, but received %d", action)
+		}
+		if action := nodeRule.Classify([]string{"src/index.ts"}); action != WATCH_SYNC {
+			t.Errorf("expected a sync, but received %d", action)
+		}
+		if action := nodeRule.Classify([]string{"README.md"}); action != WATCH_IGNORE {
+			t.Errorf("expected the change to be ignored, but received %d", action)
+		}
+	})
+}
+
+func TestReadWatchRule(t *testing.T) {
+	tmpDir := t.TempDir()
+	ConfigPath = tmpDir
+
+	t.Run("should override the paths and build step from faas.yaml", func(t *testing.T) {
+		setup(tmpDir, `function:
+  runtime: nodejs22.x
+  watch:
+    paths: [lib]
+    build: npm run compile`)
+		faasConfig, err := ReadLambdaConfig()
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		rule, err := ReadWatchRule(faasConfig)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if !slices.Equal(rule.Paths, []string{"lib"}) || rule.Build != "npm run compile" {
+			t.Errorf("unexpected watch rule %+v", rule)
+		}
+	})
+
+	t.Run("should watch the build file of gradle projects", func(t *testing.T) {
+		setup(tmpDir, `function:
+  runtime: java21`)
+		os.WriteFile(filepath.Join(tmpDir, "build.gradle"), []byte("plugins { id 'java' }"), 0644)
+		faasConfig, err := ReadLambdaConfig()
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		rule, err := ReadWatchRule(faasConfig)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if rule.Classify([]string{"build.gradle"}) != WATCH_REBUILD || rule.Build != "gradle -q jeevesLambdaLayout" || rule.Sync[0].Source != "build/lambda/classes/." {
+			t.Errorf("unexpected watch rule %+v", rule)
+		}
+	})
+}
+
+func TestChangeBatch(t *testing.T) {
+	t.Run("should collect changes until they settle", func(t *testing.T) {
+		batch := newChangeBatch(20 * time.Millisecond)
+		batch.Add("src/a.ts")
+		batch.Add("src/b.ts")
+		batch.Add("src/a.ts")
+
+		select {
+		case <-batch.Ready():
+		case <-time.After(time.Second):
+			t.Errorf("expected the batch to be ready")
+			return
+		}
+
+		if changed := batch.Flush(); !slices.Equal(changed, []string{"src/a.ts", "src/b.ts"}) {
+			t.Errorf("expected both files once, but received %v", changed)
+		}
+		if changed := batch.Flush(); len(changed) != 0 {
+			t.Errorf("expected a new batch to be empty, but received %v", changed)
+		}
+	})
+}
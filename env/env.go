@@ -1,9 +1,15 @@
 package env
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
 
 	"github.com/spf13/viper"
+	"github.com/subosito/gotenv"
 )
 
 var ConfigPath = "."
@@ -24,3 +30,44 @@ func ReadEnv() (*viper.Viper, error) {
 
 	return env, nil
 }
+
+// Reads the variables of an env file keeping the case of their names, unlike
+// viper which lowercases every key. A missing file has no variables.
+func ReadVariables(path string) (map[string]string, error) {
+	variables, err := gotenv.Read(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return variables, nil
+}
+
+// Writes the variables to an env file, sorted by name
+func WriteVariables(path string, variables map[string]string) error {
+	keys := make([]string, 0, len(variables))
+	for key := range variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	for _, key := range keys {
+		builder.WriteString(fmt.Sprintf("%s=%s\n", key, quote(variables[key])))
+	}
+
+	return os.WriteFile(path, []byte(builder.String()), 0600)
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`)
+
+// NOTE: quoting stops "$NAME" in values from being expanded when read back in
+func quote(value string) string {
+	if !strings.ContainsAny(value, "'\n\r") {
+		return fmt.Sprintf("'%s'", value)
+	}
+
+	return fmt.Sprintf(`"%s"`, escaper.Replace(value))
+}
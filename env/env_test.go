@@ -14,3 +14,46 @@ func TestReadEnv(t *testing.T) {
 		t.Errorf("expected no errors, but one was found \"%s\"", err.Error())
 	}
 }
+
+func TestVariables(t *testing.T) {
+	tmp := t.TempDir()
+	path := tmp + "/.env"
+
+	t.Run("should read no variables from a missing file", func(t *testing.T) {
+		variables, err := ReadVariables(path)
+		if err != nil {
+			t.Errorf("expected no errors, but one was found \"%s\"", err.Error())
+		}
+		if len(variables) != 0 {
+			t.Errorf("expected no variables, but found %v", variables)
+		}
+	})
+
+	t.Run("should write and read back variables keeping their case and value", func(t *testing.T) {
+		expected := map[string]string{
+			"API_URL":    "https://example.com?a=1&b=2",
+			"Mixed_Case": "it's $HOME",
+			"PRICE":      "$5",
+			"MULTILINE":  "line one\nline \"two\" costs $5",
+			"DB_PASS":    "ssm:/prod/db/password",
+		}
+
+		err := WriteVariables(path, expected)
+		if err != nil {
+			t.Errorf("expected no errors, but one was found \"%s\"", err.Error())
+			return
+		}
+
+		variables, err := ReadVariables(path)
+		if err != nil {
+			t.Errorf("expected no errors, but one was found \"%s\"", err.Error())
+			return
+		}
+
+		for key, value := range expected {
+			if variables[key] != value {
+				t.Errorf("expected %s to be \"%s\", but found \"%s\"", key, value, variables[key])
+			}
+		}
+	})
+}
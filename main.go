@@ -1,7 +1,13 @@
 package main
 
-import "github.com/obscurelyme/jeeves/cmd"
+import (
+	"os"
+
+	"github.com/obscurelyme/jeeves/cmd"
+)
 
 func main() {
-	cmd.Execute()
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,438 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/goccy/go-yaml"
+	"github.com/obscurelyme/jeeves/types"
+)
+
+// Compose service running the function
+const LAMBDA_SERVICE string = "lambda"
+
+// Host port the lambda service is published on, the runtime interface emulator listens on 8080
+const LAMBDA_HOST_PORT int = 9000
+
+// Label marking services generated by jeeves, services without it are never touched
+const MANAGED_LABEL string = "jeeves.managed"
+
+// Compose file written when the project has none
+const DEFAULT_COMPOSE_FILE string = "docker-compose.yaml"
+
+// Compose file names docker compose looks for, in order
+var ComposeFileNames = []string{"docker-compose.yaml", "docker-compose.yml", "compose.yaml", "compose.yml"}
+
+type ComposeBuild struct {
+	Context    string `yaml:"context"`
+	Dockerfile string `yaml:"dockerfile,omitempty"`
+}
+
+// Writes the short form, IE: build: ., unless another Dockerfile is used
+func (b *ComposeBuild) MarshalYAML() (interface{}, error) {
+	if b.Dockerfile == "" {
+		return b.Context, nil
+	}
+
+	return map[string]string{"context": b.Context, "dockerfile": b.Dockerfile}, nil
+}
+
+type ComposeService struct {
+	Image       string            `yaml:"image,omitempty"`
+	Build       *ComposeBuild     `yaml:"build,omitempty"`
+	Platform    string            `yaml:"platform,omitempty"`
+	Command     []string          `yaml:"command,omitempty"`
+	Ports       []string          `yaml:"ports,omitempty"`
+	EnvFile     []string          `yaml:"env_file,omitempty"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+	Volumes     []string          `yaml:"volumes,omitempty"`
+	DependsOn   []string          `yaml:"depends_on,omitempty"`
+	CapAdd      []string          `yaml:"cap_add,omitempty"`
+	SecurityOpt []string          `yaml:"security_opt,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+}
+
+// The part of a compose file generated by jeeves
+type ComposeFile struct {
+	Services map[string]*ComposeService `yaml:"services"`
+}
+
+func (cf *ComposeFile) Marshal() ([]byte, error) {
+	return yaml.Marshal(cf)
+}
+
+// A service running next to the function, IE: DynamoDB Local
+type Sidecar struct {
+	Service ComposeService
+	// Variables pointing the function at the sidecar
+	Environment map[string]string
+}
+
+// Sidecars which may be declared under function.sidecars of faas.yaml
+var Sidecars = map[string]Sidecar{
+	"dynamodb": {
+		Service: ComposeService{
+			Image:   "amazon/dynamodb-local:latest",
+			Command: []string{"-jar", "DynamoDBLocal.jar", "-sharedDb", "-inMemory"},
+			Ports:   []string{"8000:8000"},
+		},
+		// NOTE: the AWS SDKs pick up service specific endpoints from AWS_ENDPOINT_URL_<SERVICE>
+		Environment: map[string]string{"AWS_ENDPOINT_URL_DYNAMODB": "http://dynamodb:8000"},
+	},
+	"localstack": {
+		Service: ComposeService{
+			Image: "localstack/localstack:latest",
+			Ports: []string{"4566:4566"},
+		},
+		Environment: map[string]string{"AWS_ENDPOINT_URL": "http://localstack:4566"},
+	},
+	"redis": {
+		Service: ComposeService{
+			Image: "redis:7-alpine",
+			Ports: []string{"6379:6379"},
+		},
+		Environment: map[string]string{"REDIS_URL": "redis://redis:6379"},
+	},
+	"xray": {
+		Service: ComposeService{
+			Image: "amazon/aws-xray-daemon:latest",
+			// NOTE: local mode, the daemon does not look up EC2 instance metadata
+			Command: []string{"-o"},
+			Ports:   []string{"2000:2000/udp"},
+			EnvFile: []string{".env"},
+		},
+		Environment: map[string]string{"AWS_XRAY_DAEMON_ADDRESS": "xray:2000"},
+	},
+}
+
+type NewComposeFileInput struct {
+	Runtime      string
+	Architecture lambdaTypes.Architecture
+	// Names of Sidecars to run next to the function
+	Sidecars []string
+}
+
+// Builds the lambda service of the runtime and the declared sidecars
+func NewComposeFile(input *NewComposeFileInput) (*ComposeFile, error) {
+	info, err := types.LookupRuntime(input.Runtime)
+	if err != nil {
+		return nil, err
+	}
+
+	lambda := &ComposeService{
+		Build:       &ComposeBuild{Context: "."},
+		Platform:    types.DockerPlatform(input.Architecture),
+		Ports:       append([]string{fmt.Sprintf("%d:8080", LAMBDA_HOST_PORT)}, info.Compose.Ports...),
+		EnvFile:     []string{".env"},
+		Environment: map[string]string{},
+		Volumes:     slices.Clone(info.Compose.Volumes),
+		Labels:      map[string]string{MANAGED_LABEL: "true"},
+	}
+	for key, value := range info.Compose.Environment {
+		lambda.Environment[key] = value
+	}
+
+	compose := &ComposeFile{Services: map[string]*ComposeService{LAMBDA_SERVICE: lambda}}
+
+	names := slices.Clone(input.Sidecars)
+	sort.Strings(names)
+	for _, name := range slices.Compact(names) {
+		sidecar, ok := Sidecars[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown sidecar \"%s\", expected one of dynamodb, localstack, redis or xray", name)
+		}
+
+		service := sidecar.Service
+		service.Labels = map[string]string{MANAGED_LABEL: "true"}
+		compose.Services[name] = &service
+
+		lambda.DependsOn = append(lambda.DependsOn, name)
+		for key, value := range sidecar.Environment {
+			lambda.Environment[key] = value
+		}
+	}
+
+	return compose, nil
+}
+
+// Merges the services generated by jeeves into the compose file of a project,
+// keeping everything else of the file as is, including its comments
+type ComposeTemplate struct {
+	ConfigPath string
+	// Name of the compose file within ConfigPath, IE: docker-compose.yaml
+	FileName string
+	document yaml.MapSlice
+	// Comments of the file by their path, IE: $.services.postgres
+	comments yaml.CommentMap
+}
+
+// Finds the compose file of the project, false when it has none
+func FindComposeFile(configPath string) (string, bool) {
+	for _, name := range ComposeFileNames {
+		if _, err := os.Stat(filepath.Join(configPath, name)); err == nil {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+func NewComposeTemplate(configPath string) *ComposeTemplate {
+	template := new(ComposeTemplate)
+
+	template.ConfigPath = configPath
+	template.FileName = DEFAULT_COMPOSE_FILE
+	if name, found := FindComposeFile(configPath); found {
+		template.FileName = name
+	}
+
+	return template
+}
+
+// Reads the compose file, a missing file reads as an empty one
+func (com *ComposeTemplate) ReadInConfig() error {
+	com.document = yaml.MapSlice{}
+	com.comments = yaml.CommentMap{}
+
+	data, err := os.ReadFile(filepath.Join(com.ConfigPath, com.FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// NOTE: the generated header is stamped again when writing
+	body := ParseGenerated(string(data)).Body
+	err = yaml.UnmarshalWithOptions([]byte(body), &com.document, yaml.UseOrderedMap(), yaml.CommentToMap(com.comments))
+	if err != nil {
+		return fmt.Errorf("invalid compose file %s: %w", com.FileName, err)
+	}
+
+	return nil
+}
+
+// Merges the generated services into the compose file. The lambda service is replaced
+// except for keys jeeves never generates, IE: networks. Sidecars replace services of the
+// same name generated before, services written by hand are never touched and generated
+// services which are no longer declared are removed. Comments are kept, except those
+// of the keys which are generated.
+func (com *ComposeTemplate) Merge(compose *ComposeFile) error {
+	services, _ := mapValue(com.document, "services")
+
+	for i := 0; i < len(services); i++ {
+		name := fmt.Sprint(services[i].Key)
+		if _, declared := compose.Services[name]; !declared && name != LAMBDA_SERVICE && isManaged(services[i].Value) {
+			com.dropComments("services", name)
+			services = slices.Delete(services, i, i+1)
+			i--
+		}
+	}
+
+	names := make([]string, 0, len(compose.Services))
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// NOTE: the lambda service comes first in new files
+	slices.SortStableFunc(names, func(a, b string) int {
+		if a == LAMBDA_SERVICE {
+			return -1
+		}
+		if b == LAMBDA_SERVICE {
+			return 1
+		}
+		return 0
+	})
+
+	for _, name := range names {
+		generated, err := toMapSlice(compose.Services[name])
+		if err != nil {
+			return err
+		}
+
+		existing, found := mapValue(services, name)
+		switch {
+		case !found:
+			services = setMapValue(services, name, generated)
+		case name == LAMBDA_SERVICE:
+			com.dropGeneratedComments(name)
+			services = setMapValue(services, name, mergeService(existing, generated))
+		case isManaged(existing):
+			com.dropGeneratedComments(name)
+			services = setMapValue(services, name, generated)
+		}
+	}
+
+	com.document = setMapValue(com.document, "services", services)
+	return nil
+}
+
+// Names of the sidecars generated into the compose file before, services written by
+// hand are not sidecars even when named after one
+func (com *ComposeTemplate) Sidecars() []string {
+	services, _ := mapValue(com.document, "services")
+
+	names := []string{}
+	for _, item := range services {
+		name := fmt.Sprint(item.Key)
+		if _, known := Sidecars[name]; known && isManaged(item.Value) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// Whether the keys jeeves generates for the lambda service differ from those of the
+// given compose file, IE: ports edited by hand. A compose file without a lambda service
+// has no changes.
+func (com *ComposeTemplate) LambdaChanged(compose *ComposeFile) (bool, error) {
+	services, _ := mapValue(com.document, "services")
+	existing, found := mapValue(services, LAMBDA_SERVICE)
+	if !found {
+		return false, nil
+	}
+
+	current := yaml.MapSlice{}
+	for _, item := range existing {
+		if slices.Contains(generatedKeys, fmt.Sprint(item.Key)) {
+			current = append(current, item)
+		}
+	}
+
+	// NOTE: compared as plain values, the order of the keys does not matter
+	currentValues, err := toMap(current)
+	if err != nil {
+		return false, err
+	}
+	generatedValues, err := toMap(compose.Services[LAMBDA_SERVICE])
+	if err != nil {
+		return false, err
+	}
+
+	return !reflect.DeepEqual(currentValues, generatedValues), nil
+}
+
+// Content written by WriteConfig, including the generated header
+func (com *ComposeTemplate) Content() (string, error) {
+	data, err := yaml.MarshalWithOptions(com.document, yaml.WithComment(com.comments))
+	if err != nil {
+		return "", err
+	}
+
+	return Stamp(string(data)), nil
+}
+
+func (com *ComposeTemplate) WriteConfig() error {
+	content, err := com.Content()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(com.ConfigPath, com.FileName), []byte(content), 0644)
+}
+
+// Keys of ComposeService, replaced or removed on the lambda service when merging
+var generatedKeys = []string{"image", "build", "platform", "command", "ports", "env_file", "environment", "volumes", "depends_on", "cap_add", "security_opt", "labels"}
+
+// Removes the comments of the node at the path and of everything below it
+func (com *ComposeTemplate) dropComments(keys ...string) {
+	builder := (&yaml.PathBuilder{}).Root()
+	for _, key := range keys {
+		builder = builder.Child(key)
+	}
+	path := builder.Build().String()
+
+	for commented := range com.comments {
+		if commented == path || strings.HasPrefix(commented, path+".") || strings.HasPrefix(commented, path+"[") {
+			delete(com.comments, commented)
+		}
+	}
+}
+
+// Removes the comments of the generated keys of the service, keeping the comment of the service itself
+func (com *ComposeTemplate) dropGeneratedComments(service string) {
+	for _, key := range generatedKeys {
+		com.dropComments("services", service, key)
+	}
+}
+
+func mergeService(existing yaml.MapSlice, generated yaml.MapSlice) yaml.MapSlice {
+	merged := yaml.MapSlice{}
+	for _, item := range existing {
+		if !slices.Contains(generatedKeys, fmt.Sprint(item.Key)) {
+			merged = append(merged, item)
+		}
+	}
+
+	return append(generated, merged...)
+}
+
+func isManaged(service any) bool {
+	values, ok := service.(yaml.MapSlice)
+	if !ok {
+		return false
+	}
+
+	labels, _ := mapValue(values, "labels")
+	value, _ := lookup(labels, MANAGED_LABEL)
+	return fmt.Sprint(value) == "true"
+}
+
+func toMapSlice(value any) (yaml.MapSlice, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	values := yaml.MapSlice{}
+	err = yaml.UnmarshalWithOptions(data, &values, yaml.UseOrderedMap())
+	return values, err
+}
+
+func toMap(value any) (map[string]any, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]any{}
+	err = yaml.Unmarshal(data, &values)
+	return values, err
+}
+
+func lookup(values yaml.MapSlice, key string) (any, bool) {
+	for _, item := range values {
+		if fmt.Sprint(item.Key) == key {
+			return item.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// Value of the key when it holds a mapping, IE: services
+func mapValue(values yaml.MapSlice, key string) (yaml.MapSlice, bool) {
+	value, _ := lookup(values, key)
+	mapping, ok := value.(yaml.MapSlice)
+	return mapping, ok
+}
+
+func setMapValue(values yaml.MapSlice, key string, value any) yaml.MapSlice {
+	for i, item := range values {
+		if fmt.Sprint(item.Key) == key {
+			values[i].Value = value
+			return values
+		}
+	}
+
+	return append(values, yaml.MapItem{Key: key, Value: value})
+}
@@ -0,0 +1,152 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const userComposeFile = `name: orders
+services:
+  lambda:
+    build: .
+    networks:
+      - backend
+  postgres:
+    image: postgres:16
+    ports:
+      - 5432:5432
+networks:
+  backend: {}
+`
+
+func mergeCompose(t *testing.T, tmpDir string, sidecars []string) string {
+	compose, err := NewComposeFile(&NewComposeFileInput{Runtime: "python3.12", Architecture: "arm64", Sidecars: sidecars})
+	if err != nil {
+		t.Fatalf("expected no errors, but received \"%s\"", err.Error())
+	}
+
+	template := NewComposeTemplate(tmpDir)
+	err = template.ReadInConfig()
+	if err != nil {
+		t.Fatalf("expected no errors, but received \"%s\"", err.Error())
+	}
+
+	err = template.Merge(compose)
+	if err != nil {
+		t.Fatalf("expected no errors, but received \"%s\"", err.Error())
+	}
+
+	err = template.WriteConfig()
+	if err != nil {
+		t.Fatalf("expected no errors, but received \"%s\"", err.Error())
+	}
+
+	data, _ := os.ReadFile(filepath.Join(tmpDir, template.FileName))
+	return string(data)
+}
+
+func TestNewComposeFile(t *testing.T) {
+	t.Run("should point the function at its sidecars", func(t *testing.T) {
+		compose, err := NewComposeFile(&NewComposeFileInput{Runtime: "nodejs22.x", Sidecars: []string{"redis", "dynamodb"}})
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		lambda := compose.Services[LAMBDA_SERVICE]
+		if strings.Join(lambda.DependsOn, ",") != "dynamodb,redis" {
+			t.Errorf("expected the lambda to depend on dynamodb and redis, but received %v", lambda.DependsOn)
+		}
+		if lambda.Environment["AWS_ENDPOINT_URL_DYNAMODB"] != "http://dynamodb:8000" || lambda.Environment["REDIS_URL"] == "" {
+			t.Errorf("expected the sidecar endpoints in the environment, but received %v", lambda.Environment)
+		}
+		if compose.Services["dynamodb"].Image != "amazon/dynamodb-local:latest" {
+			t.Errorf("expected a dynamodb service, but received %+v", compose.Services["dynamodb"])
+		}
+	})
+
+	t.Run("should add the compose settings of the runtime from the catalog", func(t *testing.T) {
+		compose, err := NewComposeFile(&NewComposeFileInput{Runtime: "java21"})
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		lambda := compose.Services[LAMBDA_SERVICE]
+		if !strings.Contains(lambda.Environment["JAVA_TOOL_OPTIONS"], "TieredStopAtLevel=1") {
+			t.Errorf("expected the java tool options in the environment, but received %v", lambda.Environment)
+		}
+		if strings.Join(lambda.Ports, ",") != "9000:8080" {
+			t.Errorf("expected only the runtime interface emulator port, but received %v", lambda.Ports)
+		}
+	})
+
+	t.Run("should reject unknown sidecars", func(t *testing.T) {
+		_, err := NewComposeFile(&NewComposeFileInput{Runtime: "nodejs22.x", Sidecars: []string{"kafka"}})
+		if err == nil {
+			t.Errorf("expected an error for an unknown sidecar")
+		}
+	})
+}
+
+func TestComposeTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "compose.yaml"), []byte(userComposeFile), 0644)
+
+	t.Run("should merge into the existing compose file without clobbering user services", func(t *testing.T) {
+		merged := mergeCompose(t, tmpDir, []string{"localstack"})
+
+		for _, expected := range []string{"name: orders", "postgres:", "image: postgres:16", "backend", "platform: linux/arm64", "PYTHONUNBUFFERED", "localstack:", "AWS_ENDPOINT_URL: http://localstack:4566"} {
+			if !strings.Contains(merged, expected) {
+				t.Errorf("expected the compose file to contain %s, but received\n%s", expected, merged)
+			}
+		}
+
+		if strings.Index(merged, "lambda:") > strings.Index(merged, "postgres:") {
+			t.Errorf("expected the order of the services to be kept, but received\n%s", merged)
+		}
+	})
+
+	t.Run("should remove sidecars which are no longer declared", func(t *testing.T) {
+		merged := mergeCompose(t, tmpDir, nil)
+
+		if strings.Contains(merged, "localstack") {
+			t.Errorf("expected localstack to be removed, but received\n%s", merged)
+		}
+		if !strings.Contains(merged, "postgres:") {
+			t.Errorf("expected the postgres service to be kept, but received\n%s", merged)
+		}
+	})
+
+	t.Run("should keep the comments of the compose file", func(t *testing.T) {
+		commented := strings.Replace(userComposeFile, "  postgres:\n    image: postgres:16\n", "  # shared with the orders api\n  postgres:\n    image: postgres:16 # pinned to production\n", 1)
+		commented = strings.Replace(commented, "    build: .\n", "    build: . # replaced by jeeves\n", 1)
+		os.WriteFile(filepath.Join(tmpDir, "compose.yaml"), []byte(commented), 0644)
+
+		merged := mergeCompose(t, tmpDir, nil)
+		for _, expected := range []string{"# shared with the orders api", "# pinned to production"} {
+			if !strings.Contains(merged, expected) {
+				t.Errorf("expected the compose file to contain %s, but received\n%s", expected, merged)
+			}
+		}
+		if strings.Contains(merged, "# replaced by jeeves") {
+			t.Errorf("expected the comment of a generated key to be removed, but received\n%s", merged)
+		}
+
+		merged = mergeCompose(t, tmpDir, nil)
+		if strings.Count(merged, "# Generated by jeeves") != 1 {
+			t.Errorf("expected a single generated header, but received\n%s", merged)
+		}
+	})
+
+	t.Run("should not replace a service written by hand", func(t *testing.T) {
+		os.WriteFile(filepath.Join(tmpDir, "compose.yaml"), []byte(strings.Replace(userComposeFile, "networks:\n  backend: {}\n", "", 1)+"  redis:\n    image: redis:6\n"), 0644)
+
+		merged := mergeCompose(t, tmpDir, []string{"redis"})
+		if !strings.Contains(merged, "image: redis:6") || strings.Contains(merged, "redis:7-alpine") {
+			t.Errorf("expected the hand written redis service to be kept, but received\n%s", merged)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lines of unchanged context shown around every change
+const DIFF_CONTEXT int = 3
+
+type diffLine struct {
+	// ' ' for unchanged, '-' for removed and '+' for added lines
+	op   byte
+	text string
+}
+
+// Unified diff of the current file at path and the proposed content, empty when equal
+func Diff(path string, current string, proposed string) string {
+	if current == proposed {
+		return ""
+	}
+
+	lines := diffLines(splitLines(current), splitLines(proposed))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s (regenerated)\n", path, path)
+
+	for start := 0; start < len(lines); {
+		if lines[start].op == ' ' {
+			start++
+			continue
+		}
+
+		// NOTE: changes separated by at most DIFF_CONTEXT*2 unchanged lines share a hunk
+		last := start
+		for i := start; i < len(lines) && i-last <= DIFF_CONTEXT*2; i++ {
+			if lines[i].op != ' ' {
+				last = i
+			}
+		}
+
+		from := max(start-DIFF_CONTEXT, 0)
+		to := min(last+DIFF_CONTEXT+1, len(lines))
+
+		oldStart, newStart := lineNumbers(lines[:from])
+		oldCount, newCount := lineNumbers(lines[from:to])
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", oldStart+1, oldCount, newStart+1, newCount)
+		for _, line := range lines[from:to] {
+			fmt.Fprintf(&out, "%c%s\n", line.op, line.text)
+		}
+
+		start = to
+	}
+
+	return out.String()
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return []string{}
+	}
+
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// Longest common subsequence of the lines, the files are small enough for the quadratic table
+func diffLines(a []string, b []string) []diffLine {
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	lines := []diffLine{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+
+	return lines
+}
+
+// Number of lines of the current and the proposed content among the lines
+func lineNumbers(lines []diffLine) (int, int) {
+	old, proposed := 0, 0
+	for _, line := range lines {
+		if line.op != '+' {
+			old++
+		}
+		if line.op != '-' {
+			proposed++
+		}
+	}
+
+	return old, proposed
+}
@@ -0,0 +1,193 @@
+package events
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.json
+var eventFiles embed.FS
+
+// Where the body of an event lives for sources that carry one
+type bodyField struct {
+	// Dot separated path to the body within the event
+	Path string
+	// Path to the flag marking the body as base64 encoded, if the source has one
+	Base64Flag string
+	// The source always carries its body base64 encoded, IE: Kinesis
+	AlwaysBase64 bool
+	// The body is embedded as JSON rather than as a string, IE: EventBridge
+	Object bool
+}
+
+var bodyFields = map[string]bodyField{
+	"apigateway-rest": {Path: "body", Base64Flag: "isBase64Encoded"},
+	"apigateway-http": {Path: "body", Base64Flag: "isBase64Encoded"},
+	"alb":             {Path: "body", Base64Flag: "isBase64Encoded"},
+	"sqs":             {Path: "Records.0.body"},
+	"sns":             {Path: "Records.0.Sns.Message"},
+	"kinesis":         {Path: "Records.0.kinesis.data", AlwaysBase64: true},
+	"eventbridge":     {Path: "detail", Object: true},
+	"scheduled":       {Path: "detail", Object: true},
+}
+
+type GenerateInput struct {
+	// Name of the event source, see Sources
+	Source string
+	// Overrides in the form path=value, IE: Records.0.s3.bucket.name=my-bucket
+	Overrides []string
+	// Optional: body to place in the source's body field
+	Body *string
+	// Base64 encode the body, for sources which support it
+	Base64 bool
+}
+
+// Lists the event sources that have an embedded template
+func Sources() []string {
+	entries, _ := eventFiles.ReadDir(".")
+	sources := []string{}
+	for _, entry := range entries {
+		sources = append(sources, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(sources)
+
+	return sources
+}
+
+// Generates a sample event for the given source, applying the body and overrides
+func Generate(input *GenerateInput) ([]byte, error) {
+	data, err := eventFiles.ReadFile(fmt.Sprintf("%s.json", input.Source))
+	if err != nil {
+		return nil, fmt.Errorf("no sample event for \"%s\", expected one of %s", input.Source, strings.Join(Sources(), ", "))
+	}
+
+	event, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Body != nil {
+		event, err = setBody(event, input.Source, *input.Body, input.Base64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, override := range input.Overrides {
+		path, value, found := strings.Cut(override, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid override \"%s\", expected path=value", override)
+		}
+
+		event, err = Set(event, path, parseValue(value))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return json.MarshalIndent(event, "", "  ")
+}
+
+func setBody(event any, source string, body string, encode bool) (any, error) {
+	field, ok := bodyFields[source]
+	if !ok {
+		return nil, fmt.Errorf("%s events do not carry a body, use --set instead", source)
+	}
+
+	if field.Object {
+		value, err := decode([]byte(body))
+		if err != nil {
+			return nil, fmt.Errorf("%s events require a JSON body: %s", source, err.Error())
+		}
+		return Set(event, field.Path, value)
+	}
+
+	if encode && field.Base64Flag == "" && !field.AlwaysBase64 {
+		return nil, fmt.Errorf("%s events do not support base64 encoded bodies", source)
+	}
+
+	var err error
+	if encode || field.AlwaysBase64 {
+		body = base64.StdEncoding.EncodeToString([]byte(body))
+	}
+
+	if field.Base64Flag != "" {
+		event, err = Set(event, field.Base64Flag, encode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return Set(event, field.Path, body)
+}
+
+// Sets the value at the dot separated path, numeric segments index into arrays.
+// Missing object keys are created along the way.
+func Set(doc any, path string, value any) (any, error) {
+	if path == "" {
+		return value, nil
+	}
+
+	key, rest, _ := strings.Cut(path, ".")
+
+	switch node := doc.(type) {
+	case map[string]any:
+		child, err := Set(node[key], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		node[key] = child
+		return node, nil
+	case []any:
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(node) {
+			return nil, fmt.Errorf("invalid array index \"%s\" in path", key)
+		}
+		child, err := Set(node[index], rest, value)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = child
+		return node, nil
+	case nil:
+		child, err := Set(nil, rest, value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{key: child}, nil
+	}
+
+	return nil, errors.New("cannot set a field on a non object value")
+}
+
+// Values which are valid JSON (numbers, booleans, objects...) are used as is,
+// anything else is treated as a string.
+func parseValue(value string) any {
+	parsed, err := decode([]byte(value))
+	if err != nil {
+		return value
+	}
+
+	return parsed
+}
+
+func decode(data []byte) (any, error) {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+
+	return value, nil
+}
@@ -0,0 +1,77 @@
+package events
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+func generate(t *testing.T, input *GenerateInput) map[string]any {
+	data, err := Generate(input)
+	if err != nil {
+		t.Fatalf("expected no errors, but received \"%s\"", err.Error())
+	}
+
+	var event map[string]any
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("generated event is not valid JSON: %s", err.Error())
+	}
+
+	return event
+}
+
+func TestGenerateEvents(t *testing.T) {
+	t.Run("should generate every embedded source", func(t *testing.T) {
+		for _, source := range Sources() {
+			generate(t, &GenerateInput{Source: source})
+		}
+	})
+
+	t.Run("should apply overrides into arrays", func(t *testing.T) {
+		event := generate(t, &GenerateInput{
+			Source:    "s3-put",
+			Overrides: []string{"Records.0.s3.bucket.name=my-bucket", "Records.0.s3.object.size=42"},
+		})
+
+		s3 := event["Records"].([]any)[0].(map[string]any)["s3"].(map[string]any)
+		if s3["bucket"].(map[string]any)["name"] != "my-bucket" {
+			t.Errorf("bucket name was not overridden")
+		}
+		if s3["object"].(map[string]any)["size"] != float64(42) {
+			t.Errorf("expected object size to be the number 42, received %v", s3["object"].(map[string]any)["size"])
+		}
+	})
+
+	t.Run("should base64 encode API Gateway bodies", func(t *testing.T) {
+		body := "hello"
+		event := generate(t, &GenerateInput{Source: "apigateway-http", Body: &body, Base64: true})
+
+		if event["body"] != base64.StdEncoding.EncodeToString([]byte(body)) || event["isBase64Encoded"] != true {
+			t.Errorf("expected an encoded body, received %v", event["body"])
+		}
+	})
+
+	t.Run("should always encode Kinesis data", func(t *testing.T) {
+		body := "hello"
+		event := generate(t, &GenerateInput{Source: "kinesis", Body: &body})
+
+		kinesis := event["Records"].([]any)[0].(map[string]any)["kinesis"].(map[string]any)
+		if kinesis["data"] != base64.StdEncoding.EncodeToString([]byte(body)) {
+			t.Errorf("expected kinesis data to be encoded, received %v", kinesis["data"])
+		}
+	})
+
+	t.Run("should reject unknown sources", func(t *testing.T) {
+		_, err := Generate(&GenerateInput{Source: "carrier-pigeon"})
+		if err == nil {
+			t.Errorf("expected an error for an unknown source")
+		}
+	})
+
+	t.Run("should reject invalid array indexes", func(t *testing.T) {
+		_, err := Generate(&GenerateInput{Source: "sqs", Overrides: []string{"Records.5.body=hi"}})
+		if err == nil {
+			t.Errorf("expected an error for an out of range index")
+		}
+	})
+}
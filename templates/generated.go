@@ -0,0 +1,118 @@
+package templates
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version of the embedded templates, bump it whenever a template changes so
+// projects generated before are reported as outdated
+const TEMPLATE_VERSION int = 6
+
+// NOTE: a comment in both Dockerfiles and compose files
+var generatedHeader = regexp.MustCompile(`^# Generated by jeeves from template v(\d+) \(sha256:([0-9a-f]+)\)`)
+
+// State of a generated file within the project
+type GeneratedState int
+
+const (
+	// The file does not exist yet
+	GENERATED_MISSING GeneratedState = iota
+	// Generated from the current templates and not edited since
+	GENERATED_CURRENT
+	// Generated from older templates and not edited since
+	GENERATED_OUTDATED
+	// Edited by hand after it was generated
+	GENERATED_EDITED
+	// Has no header, IE: written by hand or before headers were added
+	GENERATED_UNTRACKED
+)
+
+func (s GeneratedState) String() string {
+	switch s {
+	case GENERATED_MISSING:
+		return "missing"
+	case GENERATED_CURRENT:
+		return "current"
+	case GENERATED_OUTDATED:
+		return "outdated"
+	case GENERATED_EDITED:
+		return "edited"
+	}
+
+	return "untracked"
+}
+
+// A file split into its header and the content below it
+type GeneratedFile struct {
+	// Template version of the header, 0 without a header
+	Version int
+	// Hash of the header, empty without a header
+	Hash string
+	// Content below the header, the whole file without a header
+	Body string
+}
+
+// Whether the file carries a header
+func (gf GeneratedFile) Stamped() bool {
+	return gf.Hash != ""
+}
+
+func (gf GeneratedFile) State() GeneratedState {
+	switch {
+	case !gf.Stamped():
+		return GENERATED_UNTRACKED
+	case contentHash(gf.Body) != gf.Hash:
+		return GENERATED_EDITED
+	case gf.Version < TEMPLATE_VERSION:
+		return GENERATED_OUTDATED
+	}
+
+	return GENERATED_CURRENT
+}
+
+// Prepends the header with the current template version and the hash of the body
+func Stamp(body string) string {
+	return fmt.Sprintf("# Generated by jeeves from template v%d (sha256:%s), run \"jeeves faas templates status\" to check for edits\n%s", TEMPLATE_VERSION, contentHash(body), body)
+}
+
+func ParseGenerated(content string) GeneratedFile {
+	header, body, found := strings.Cut(content, "\n")
+	if !found {
+		return GeneratedFile{Body: content}
+	}
+
+	match := generatedHeader.FindStringSubmatch(header)
+	if match == nil {
+		return GeneratedFile{Body: content}
+	}
+
+	version, _ := strconv.Atoi(match[1])
+	return GeneratedFile{Version: version, Hash: match[2], Body: body}
+}
+
+// Reads the generated file at path, GENERATED_MISSING when it does not exist
+func ReadGenerated(path string) (GeneratedFile, GeneratedState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return GeneratedFile{}, GENERATED_MISSING, nil
+	}
+	if err != nil {
+		return GeneratedFile{}, GENERATED_MISSING, err
+	}
+
+	file := ParseGenerated(string(data))
+	return file, file.State(), nil
+}
+
+// NOTE: 16 characters are plenty to detect edits
+func contentHash(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])[:16]
+}
@@ -0,0 +1,102 @@
+package templates
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerated(t *testing.T) {
+	const body = "FROM amazon/aws-lambda-nodejs:22\n\nCMD [ \"dist/index.js\" ]\n"
+
+	t.Run("should report a stamped file as current", func(t *testing.T) {
+		generated := ParseGenerated(Stamp(body))
+		if generated.Body != body || generated.Version != TEMPLATE_VERSION {
+			t.Errorf("expected the body and template version to be parsed, but received %+v", generated)
+		}
+		if generated.State() != GENERATED_CURRENT {
+			t.Errorf("expected current, but received %s", generated.State())
+		}
+	})
+
+	t.Run("should detect edits below the header", func(t *testing.T) {
+		edited := strings.Replace(Stamp(body), "nodejs:22", "nodejs:20", 1)
+		if state := ParseGenerated(edited).State(); state != GENERATED_EDITED {
+			t.Errorf("expected edited, but received %s", state)
+		}
+	})
+
+	t.Run("should report files of older templates as outdated", func(t *testing.T) {
+		outdated := strings.Replace(Stamp(body), fmt.Sprintf("template v%d ", TEMPLATE_VERSION), "template v0 ", 1)
+		if state := ParseGenerated(outdated).State(); state != GENERATED_OUTDATED {
+			t.Errorf("expected outdated, but received %s", state)
+		}
+	})
+
+	t.Run("should report files without a header as untracked", func(t *testing.T) {
+		generated := ParseGenerated("# a comment\n" + body)
+		if generated.State() != GENERATED_UNTRACKED || generated.Body != "# a comment\n"+body {
+			t.Errorf("expected an untracked file, but received %+v", generated)
+		}
+	})
+
+	t.Run("should report missing files", func(t *testing.T) {
+		_, state, err := ReadGenerated(filepath.Join(t.TempDir(), "Dockerfile"))
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if state != GENERATED_MISSING {
+			t.Errorf("expected missing, but received %s", state)
+		}
+	})
+
+	t.Run("should read the state of a written file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "Dockerfile")
+		os.WriteFile(path, []byte(Stamp(body)), 0644)
+
+		generated, state, err := ReadGenerated(path)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if state != GENERATED_CURRENT || generated.Body != body {
+			t.Errorf("expected a current file, but received %s", state)
+		}
+	})
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("should be empty for equal content", func(t *testing.T) {
+		if diff := Diff("Dockerfile", "a\nb\n", "a\nb\n"); diff != "" {
+			t.Errorf("expected no diff, but received\n%s", diff)
+		}
+	})
+
+	t.Run("should show the changed lines with context", func(t *testing.T) {
+		current := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+		proposed := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+
+		const expected = `--- Dockerfile
++++ Dockerfile (regenerated)
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+		if diff := Diff("Dockerfile", current, proposed); diff != expected {
+			t.Errorf("unexpected diff\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+//go:embed files/*.template
+var embeddedTemplates embed.FS
+
+// Directory within the project whose templates override the user's and the embedded ones
+const PROJECT_TEMPLATE_DIR string = ".jeeves/templates"
+
+// Source of templates which were not overridden
+const EMBEDDED_TEMPLATE_SOURCE string = "embedded"
+
+// Directory of the user's templates, which override the embedded ones for every project
+var UserTemplateDir func() (string, error)
+
+func init() {
+	UserTemplateDir = userTemplateDir
+}
+
+// IE: ~/.config/jeeves/templates on Linux
+func userTemplateDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "jeeves", "templates"), nil
+}
+
+// Names of the embedded templates, IE: dockerfile.nodejs.template
+func TemplateNames() []string {
+	entries, _ := fs.ReadDir(embeddedTemplates, "files")
+
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	return names
+}
+
+// Finds the template in the project's PROJECT_TEMPLATE_DIR, then the user's template
+// directory and falls back to the embedded one. Returns the template and the path it
+// was read from, EMBEDDED_TEMPLATE_SOURCE for the embedded one.
+func LookupTemplate(projectPath string, name string) (string, string, error) {
+	dirs := []string{filepath.Join(projectPath, PROJECT_TEMPLATE_DIR)}
+	// NOTE: without a user config directory only the project overrides templates
+	if userDir, err := UserTemplateDir(); err == nil {
+		dirs = append(dirs, userDir)
+	}
+
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return string(data), path, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", "", err
+		}
+	}
+
+	data, err := embeddedTemplates.ReadFile("files/" + name)
+	if err != nil {
+		return "", "", fmt.Errorf("no template named %s", name)
+	}
+
+	return string(data), EMBEDDED_TEMPLATE_SOURCE, nil
+}
+
+// Renders the template found by LookupTemplate with the data
+func RenderTemplate(projectPath string, name string, data any) (string, error) {
+	content, source, err := LookupTemplate(projectPath, name)
+	if err != nil {
+		return "", err
+	}
+
+	// NOTE: fail on typos such as {{ .Imgae }} instead of rendering "<no value>"
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %s: %w", source, err)
+	}
+
+	var out bytes.Buffer
+	err = tmpl.Execute(&out, data)
+	if err != nil {
+		return "", fmt.Errorf("could not render template %s: %w", source, err)
+	}
+
+	return out.String(), nil
+}
+
+// Copies the embedded template into the directory for editing, an existing
+// template is never overwritten. Returns the path of the copy.
+func EjectTemplate(dir string, name string) (string, error) {
+	data, err := embeddedTemplates.ReadFile("files/" + name)
+	if err != nil {
+		return "", fmt.Errorf("no template named %s", name)
+	}
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, name)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return path, err
+	}
+	defer file.Close()
+
+	_, err = file.Write(data)
+	return path, err
+}
@@ -0,0 +1,200 @@
+package templates
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/obscurelyme/jeeves/types"
+)
+
+func TestLookupTemplate(t *testing.T) {
+	projectDir := t.TempDir()
+	userDir := t.TempDir()
+	UserTemplateDir = func() (string, error) {
+		return userDir, nil
+	}
+	defer func() { UserTemplateDir = userTemplateDir }()
+
+	const name = "dockerfile.nodejs.template"
+
+	t.Run("should fall back to the embedded template", func(t *testing.T) {
+		_, source, err := LookupTemplate(projectDir, name)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if source != EMBEDDED_TEMPLATE_SOURCE {
+			t.Errorf("expected the embedded template, but received %s", source)
+		}
+	})
+
+	t.Run("should prefer the user's template over the embedded one", func(t *testing.T) {
+		os.WriteFile(filepath.Join(userDir, name), []byte("FROM user"), 0644)
+
+		content, source, err := LookupTemplate(projectDir, name)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if content != "FROM user" || source != filepath.Join(userDir, name) {
+			t.Errorf("expected the user's template, but received %s", source)
+		}
+	})
+
+	t.Run("should prefer the project's template over the user's", func(t *testing.T) {
+		dir := filepath.Join(projectDir, PROJECT_TEMPLATE_DIR)
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, name), []byte("FROM project"), 0644)
+
+		content, _, err := LookupTemplate(projectDir, name)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if content != "FROM project" {
+			t.Errorf("expected the project's template, but received %s", content)
+		}
+	})
+
+	t.Run("should fail for unknown templates", func(t *testing.T) {
+		_, _, err := LookupTemplate(projectDir, "dockerfile.cobol.template")
+		if err == nil {
+			t.Errorf("expected an error for an unknown template")
+		}
+	})
+}
+
+func TestRenderDockerFile(t *testing.T) {
+	projectDir := t.TempDir()
+	UserTemplateDir = func() (string, error) {
+		return "", errors.New("no user config directory")
+	}
+	defer func() { UserTemplateDir = userTemplateDir }()
+
+	t.Run("should set the variables of the context with ENV", func(t *testing.T) {
+		dockerFile, err := NewDockerFile(&NewDockerFileInput{
+			Runtime:  "nodejs22.x",
+			Handler:  "dist/index.js",
+			FilePath: projectDir,
+			Env:      map[string]string{"NODE_EXTRA_CA_CERTS": "/etc/ssl/internal.pem"},
+		})
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		body := ParseGenerated(dockerFile.Content()).Body
+		if !strings.HasPrefix(body, "FROM --platform=linux/arm64 amazon/aws-lambda-nodejs:22\nENV NODE_EXTRA_CA_CERTS=\"/etc/ssl/internal.pem\"\n\nCOPY") {
+			t.Errorf("unexpected Dockerfile\n%s", body)
+		}
+	})
+
+	t.Run("should render the project's template", func(t *testing.T) {
+		dir := filepath.Join(projectDir, PROJECT_TEMPLATE_DIR)
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "dockerfile.ruby.template"), []byte("FROM registry.example.com/{{ .Image }}:{{ .Tag }}\nARG GEM_SOURCE\nCMD [ \"{{ .Handler }}\" ]"), 0644)
+
+		dockerFile, err := NewDockerFile(&NewDockerFileInput{Runtime: "ruby3.3", Handler: "handler.handler", FilePath: projectDir})
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		const expected = "FROM registry.example.com/amazon/aws-lambda-ruby:3.3\nARG GEM_SOURCE\nCMD [ \"handler.handler\" ]"
+		if body := ParseGenerated(dockerFile.Content()).Body; body != expected {
+			t.Errorf("unexpected Dockerfile\n%s", body)
+		}
+	})
+
+	t.Run("should fail on unknown fields of the context", func(t *testing.T) {
+		os.WriteFile(filepath.Join(projectDir, PROJECT_TEMPLATE_DIR, "dockerfile.go.template"), []byte("FROM {{ .Imgae }}"), 0644)
+
+		_, err := NewDockerFile(&NewDockerFileInput{Runtime: "provided.al2023", FilePath: projectDir})
+		if err == nil {
+			t.Errorf("expected an error for an unknown field")
+		}
+	})
+}
+
+func TestEjectTemplate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), PROJECT_TEMPLATE_DIR)
+
+	t.Run("should copy the embedded template", func(t *testing.T) {
+		path, err := EjectTemplate(dir, "dockerfile.java.template")
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		data, _ := os.ReadFile(path)
+		embedded, _ := embeddedTemplates.ReadFile("files/dockerfile.java.template")
+		if string(data) != string(embedded) {
+			t.Errorf("expected the embedded template, but received\n%s", data)
+		}
+	})
+
+	t.Run("should not overwrite an ejected template", func(t *testing.T) {
+		os.WriteFile(filepath.Join(dir, "dockerfile.java.template"), []byte("FROM edited"), 0644)
+
+		_, err := EjectTemplate(dir, "dockerfile.java.template")
+		if !errors.Is(err, fs.ErrExist) {
+			t.Errorf("expected the template to exist, but received %v", err)
+		}
+	})
+}
+
+func TestContainerDockerFile(t *testing.T) {
+	projectDir := t.TempDir()
+	UserTemplateDir = func() (string, error) {
+		return "", errors.New("no user config directory")
+	}
+	defer func() { UserTemplateDir = userTemplateDir }()
+
+	cases := []struct {
+		runtime  string
+		expected []string
+	}{
+		{"nodejs22.x", []string{"FROM --platform=linux/amd64 node:22 AS build", "COPY --from=build /build/dist ${LAMBDA_TASK_ROOT}/dist"}},
+		{"provided.al2023", []string{"FROM --platform=$BUILDPLATFORM golang:1 AS build", "GOARCH=amd64", "FROM --platform=linux/amd64 amazon/aws-lambda-provided:al2023"}},
+		{"java21", []string{"FROM --platform=linux/amd64 maven:3-amazoncorretto-21 AS build", "COPY --from=build /build/target/classes ${LAMBDA_TASK_ROOT}"}},
+		{"python3.12", []string{"FROM --platform=linux/amd64 python:3.12 AS build", "COPY --from=build /packages ${LAMBDA_TASK_ROOT}"}},
+	}
+
+	for _, c := range cases {
+		t.Run("should build "+c.runtime+" within a builder stage", func(t *testing.T) {
+			// NOTE: container builds need neither a venv nor a maven driver
+			dockerFile, err := NewDockerFile(&NewDockerFileInput{
+				Runtime:      c.runtime,
+				Handler:      "handler",
+				Architecture: "x86_64",
+				FilePath:     projectDir,
+				Build:        types.BUILD_CONTAINER,
+			})
+			if err != nil {
+				t.Errorf("expected no errors, but received \"%s\"", err.Error())
+				return
+			}
+
+			for _, expected := range c.expected {
+				if !strings.Contains(dockerFile.Content(), expected) {
+					t.Errorf("expected the Dockerfile to contain %s, but received\n%s", expected, dockerFile.Content())
+				}
+			}
+		})
+	}
+
+	t.Run("should keep the regular ruby Dockerfile", func(t *testing.T) {
+		dockerFile, err := NewDockerFile(&NewDockerFileInput{Runtime: "ruby3.3", Handler: "handler.handler", FilePath: projectDir, Build: types.BUILD_CONTAINER})
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if !strings.Contains(dockerFile.Content(), "bundle install") {
+			t.Errorf("unexpected ruby Dockerfile\n%s", dockerFile.Content())
+		}
+	})
+}
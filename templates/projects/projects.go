@@ -0,0 +1,168 @@
+package projects
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/obscurelyme/jeeves/types"
+)
+
+//go:embed all:files
+var projectFiles embed.FS
+
+// Files shared by every runtime
+const COMMON_TEMPLATES string = "common"
+
+// Commands which build each language's artifacts in the layout the Dockerfile templates expect
+var buildCommands = map[types.LambdaLanguage]string{
+	types.NodeJs: "npm install && npm run build",
+	types.Golang: "go mod tidy && make build",
+	types.Java:   "mvn package dependency:copy-dependencies -DincludeScope=runtime",
+	types.Python: "python3 -m venv .venv && source .venv/bin/activate && pip install .",
+	types.Ruby:   "bundle install",
+}
+
+// Values available to the project templates
+type ProjectData struct {
+	Name     string
+	Runtime  string
+	Handler  string
+	Language types.LambdaLanguage
+	// IE: npm install && npm run build
+	BuildCommand string
+	// IE: 3.12 for python3.12
+	PythonVersion string
+	// IE: 21 for java21
+	JavaVersion string
+	// IE: 3.3 for ruby3.3
+	RubyVersion string
+	// Lambda architecture, IE: arm64 or x86_64
+	Architecture string
+}
+
+func NewProjectData(name string, runtime *types.LambdaRuntime) *ProjectData {
+	return &ProjectData{
+		Name:          name,
+		Runtime:       string(runtime.AWSRuntime),
+		Handler:       runtime.Handler,
+		Language:      runtime.Language,
+		BuildCommand:  buildCommands[runtime.Language],
+		PythonVersion: strings.TrimPrefix(string(runtime.AWSRuntime), "python"),
+		JavaVersion:   strings.TrimPrefix(string(runtime.AWSRuntime), "java"),
+		RubyVersion:   strings.TrimPrefix(string(runtime.AWSRuntime), "ruby"),
+		Architecture:  string(types.DEFAULT_ARCHITECTURE),
+	}
+}
+
+// GOARCH of the architecture, IE: amd64 for x86_64
+func (d *ProjectData) GoArch() string {
+	if d.Architecture == string(lambdaTypes.ArchitectureX8664) {
+		return "amd64"
+	}
+
+	return "arm64"
+}
+
+// Renders the project files of the language, keyed by their path within the project
+func Render(data *ProjectData) (map[string][]byte, error) {
+	root := path.Join("files", string(data.Language))
+	if _, err := fs.Stat(projectFiles, root); err != nil {
+		return nil, fmt.Errorf("no project template for the \"%s\" language", data.Language)
+	}
+
+	files := map[string][]byte{}
+	for _, dir := range []string{path.Join("files", COMMON_TEMPLATES), root} {
+		err := fs.WalkDir(projectFiles, dir, func(name string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			relative := strings.TrimPrefix(name, dir+"/")
+			content, err := render(name, data)
+			if err != nil {
+				return err
+			}
+
+			files[targetPath(relative)] = content
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// Renders the project into dir, which must not exist or be empty
+func Write(dir string, data *ProjectData) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err == nil && len(entries) > 0 {
+		return nil, fmt.Errorf("%s already exists and is not empty", dir)
+	}
+
+	files, err := Render(data)
+	if err != nil {
+		return nil, err
+	}
+
+	written := []string{}
+	for name, content := range files {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+
+		err = os.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return nil, err
+		}
+
+		err = os.WriteFile(target, content, 0644)
+		if err != nil {
+			return nil, err
+		}
+		written = append(written, name)
+	}
+
+	return written, nil
+}
+
+func render(name string, data *ProjectData) ([]byte, error) {
+	raw, err := projectFiles.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(path.Base(name)).Option("missingkey=error").Parse(string(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Templates are stored with a .tmpl suffix so files such as go.mod are not
+// picked up by the Go tooling, and dotfiles with a "dot-" prefix.
+// IE: dot-gitignore.tmpl -> .gitignore
+func targetPath(name string) string {
+	name = strings.TrimSuffix(name, ".tmpl")
+
+	dir, base := path.Split(name)
+	if trimmed, found := strings.CutPrefix(base, "dot-"); found {
+		base = "." + trimmed
+	}
+
+	return dir + base
+}
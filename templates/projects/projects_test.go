@@ -0,0 +1,134 @@
+package projects
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/obscurelyme/jeeves/types"
+)
+
+func TestRender(t *testing.T) {
+	expectedFiles := map[types.LambdaLanguage][]string{
+		types.NodeJs: {"package.json", "tsconfig.json", "src/index.ts", ".gitignore"},
+		types.Golang: {"go.mod", "main.go", "Makefile", ".gitignore"},
+		types.Java:   {"pom.xml", "src/main/java/com/example/app/Handler.java", ".gitignore"},
+		types.Python: {"pyproject.toml", "src/handler.py", ".gitignore"},
+		types.Ruby:   {"Gemfile", "handler.rb", ".gitignore"},
+	}
+
+	for _, runtime := range types.RuntimeSelectionOptions {
+		t.Run("should render the "+string(runtime.Language)+" project", func(t *testing.T) {
+			files, err := Render(NewProjectData("my-function", &runtime))
+			if err != nil {
+				t.Errorf("expected no errors, but received \"%s\"", err.Error())
+				return
+			}
+
+			for _, name := range append(expectedFiles[runtime.Language], "faas.yaml", ".dockerignore", "README.md") {
+				content, ok := files[name]
+				if !ok {
+					t.Errorf("expected %s to be rendered", name)
+					continue
+				}
+				if bytes.Contains(content, []byte("<no value>")) {
+					t.Errorf("expected %s to have every value filled in", name)
+				}
+			}
+
+			expectedYaml := "function:\n  name: my-function\n  runtime: " + string(runtime.AWSRuntime) + "\n  handler: " + runtime.Handler + "\n  architecture: arm64\n"
+			if string(files["faas.yaml"]) != expectedYaml {
+				t.Errorf("expected faas.yaml to be \"%s\", but received \"%s\"", expectedYaml, files["faas.yaml"])
+			}
+		})
+	}
+}
+
+func TestRenderArchitecture(t *testing.T) {
+	t.Run("should build go binaries for the selected architecture", func(t *testing.T) {
+		runtime, err := findOption(types.Golang)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data := NewProjectData("my-function", &runtime)
+		data.Architecture = "x86_64"
+
+		files, err := Render(data)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if !bytes.Contains(files["Makefile"], []byte("GOARCH=amd64")) {
+			t.Errorf("expected the Makefile to build for amd64, but received \"%s\"", files["Makefile"])
+		}
+		if !bytes.Contains(files["faas.yaml"], []byte("architecture: x86_64")) {
+			t.Errorf("expected faas.yaml to select x86_64, but received \"%s\"", files["faas.yaml"])
+		}
+	})
+}
+
+func findOption(language types.LambdaLanguage) (types.LambdaRuntime, error) {
+	for _, option := range types.RuntimeSelectionOptions {
+		if option.Language == language {
+			return option, nil
+		}
+	}
+
+	return types.LambdaRuntime{}, fmt.Errorf("no %s runtime", language)
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("should write the project into a new directory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "my-function")
+		info, err := types.LookupRuntime("nodejs22.x")
+		if err != nil {
+			t.Fatal(err)
+		}
+		runtime := info.Option()
+
+		_, err = Write(dir, NewProjectData("my-function", &runtime))
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, "src", "index.ts")); err != nil {
+			t.Errorf("expected src/index.ts to be written, but received \"%s\"", err.Error())
+		}
+	})
+
+	t.Run("should refuse to write into a directory which is not empty", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "existing.txt"), []byte("keep me"), 0644)
+		info, err := types.LookupRuntime("nodejs22.x")
+		if err != nil {
+			t.Fatal(err)
+		}
+		runtime := info.Option()
+
+		_, err = Write(dir, NewProjectData("my-function", &runtime))
+		if err == nil {
+			t.Errorf("expected an error for a directory which is not empty")
+		}
+	})
+}
+
+func TestTargetPath(t *testing.T) {
+	t.Run("should strip the template suffix and restore dotfiles", func(t *testing.T) {
+		cases := map[string]string{
+			"dot-gitignore.tmpl":  ".gitignore",
+			"go.mod.tmpl":         "go.mod",
+			"src/handler.py.tmpl": "src/handler.py",
+		}
+
+		for name, expected := range cases {
+			if actual := targetPath(name); actual != expected {
+				t.Errorf("expected %s to become %s, but received %s", name, expected, actual)
+			}
+		}
+	})
+}
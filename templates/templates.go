@@ -1,98 +1,161 @@
 package templates
 
 import (
-	_ "embed"
 	"errors"
 	"fmt"
 	"os"
-	"regexp"
-	"strings"
 
 	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	"github.com/obscurelyme/jeeves/types"
 	"github.com/obscurelyme/jeeves/utils/java"
 	pythonUtils "github.com/obscurelyme/jeeves/utils/python"
-	"github.com/spf13/viper"
 )
 
-//go:embed files/dockerfile.nodejs.template
-var dockerFileNodeJS string
-
-//go:embed files/dockerfile.python.template
-var dockerFilePython string
-
-//go:embed files/dockerfile.java.template
-var dockerFileJava string
-
-//go:embed files/dockerfile.go.template
-var dockerFileGo string
-
-func GetDockerTemplate(runtime lambdaTypes.Runtime) (string, error) {
-	switch runtime {
-	case lambdaTypes.RuntimeNodejs20x:
-		return dockerFileNodeJS, nil
-	case lambdaTypes.RuntimeNodejs18x:
-		return dockerFileNodeJS, nil
-	case lambdaTypes.RuntimeJava21:
-		return dockerFileJava, nil
-	case lambdaTypes.RuntimeJava17:
-		return dockerFileJava, nil
-	case lambdaTypes.RuntimeProvidedal2023:
-		return dockerFileGo, nil
-	case lambdaTypes.RuntimePython39:
-		return dockerFilePython, nil
-	case lambdaTypes.RuntimePython310:
-		return dockerFilePython, nil
-	case lambdaTypes.RuntimePython311:
-		return dockerFilePython, nil
-	case lambdaTypes.RuntimePython312:
-		return dockerFilePython, nil
-	}
-
-	return "", errors.New("no dockerfile template supports the provided runtime")
-}
-
-func GetDockerfile(runtime lambdaTypes.Runtime, image string, tag string, handler string) (string, error) {
-	template, err := GetDockerTemplate(runtime)
+// Dockerfile templates by language
+var dockerTemplates = map[types.LambdaLanguage]string{
+	types.NodeJs: "dockerfile.nodejs.template",
+	types.Python: "dockerfile.python.template",
+	types.Java:   "dockerfile.java.template",
+	types.Golang: "dockerfile.go.template",
+	types.Ruby:   "dockerfile.ruby.template",
+}
+
+// Dockerfile templates with a builder stage by language, used with types.BUILD_CONTAINER.
+// NOTE: ruby installs its gems within the Lambda image with either build
+var containerTemplates = map[types.LambdaLanguage]string{
+	types.NodeJs: "dockerfile.nodejs.container.template",
+	types.Python: "dockerfile.python.container.template",
+	types.Java:   "dockerfile.java.container.template",
+	types.Golang: "dockerfile.go.container.template",
+}
+
+// Template of the Dockerfile.debug of Go functions
+const GO_DEBUG_TEMPLATE string = "dockerfile.go.debug.template"
+
+// Dockerfile template with a builder stage of Java functions built with gradle
+const GRADLE_CONTAINER_TEMPLATE string = "dockerfile.java.gradle.container.template"
+
+// Name of the Dockerfile template of the runtime and build, IE: dockerfile.nodejs.template
+func DockerTemplateName(runtime string, build types.BuildMode) (string, error) {
+	info, err := types.LookupRuntime(runtime)
 	if err != nil {
-		return "", err
+		return "", errors.New("no dockerfile template supports the provided runtime")
+	}
+
+	if name, ok := containerTemplates[info.Language]; ok && build == types.BUILD_CONTAINER {
+		return name, nil
 	}
 
-	if runtime == lambdaTypes.RuntimeProvidedal2023 || runtime == lambdaTypes.RuntimeProvidedal2 {
-		// NOTE: Golang requires no handler to be specified, executable named "bootstrap" is mandatory
-		return fmt.Sprintf(template, image, tag), nil
+	name, ok := dockerTemplates[info.Language]
+	if !ok {
+		return "", errors.New("no dockerfile template supports the provided runtime")
 	}
 
-	return fmt.Sprintf(template, image, tag, handler), nil
+	return name, nil
 }
 
-type ComposeTemplate struct {
-	Cfg        *viper.Viper
-	ConfigPath string
+// Values available to Dockerfile templates, IE: FROM {{ .Image }}:{{ .Tag }}
+type DockerfileContext struct {
+	Runtime  string
+	Language types.LambdaLanguage
+	Image    string
+	Tag      string
+	Handler  string
+	// Dependencies copied into the image, used for Python and Java
+	DepsPath string
+	// Compiled classes copied into the image, used for Java
+	ClassesPath  string
+	Architecture lambdaTypes.Architecture
+	// Docker platform of the architecture, IE: linux/arm64
+	Platform string
+	Build    types.BuildMode
+	// Image of the builder stage with types.BUILD_CONTAINER, IE: node:22
+	Builder string
+	// Variables set with ENV
+	Env map[string]string
+	// Port the debugger listens on, used for debug Dockerfiles
+	DebugPort int
+	// Renders the image deployed to Lambda, which leaves out the debug tooling of local runs
+	Deploy bool
 }
 
-func (com *ComposeTemplate) ReadInConfig() error {
-	return nil
+// GOARCH of the architecture, IE: amd64 for x86_64
+func (c *DockerfileContext) GoArch() string {
+	if c.Architecture == lambdaTypes.ArchitectureX8664 {
+		return "amd64"
+	}
+
+	return "arm64"
 }
 
-func (com *ComposeTemplate) WriteConfig() error {
-	return nil
+func newDockerfileContext(input *NewDockerFileInput) (*DockerfileContext, error) {
+	info, err := types.LookupRuntime(input.Runtime)
+	if err != nil {
+		return nil, errors.New("no docker image supports given runtime")
+	}
+
+	architecture := input.Architecture
+	if architecture == "" {
+		architecture = types.DEFAULT_ARCHITECTURE
+	}
+
+	env := input.Env
+	if env == nil {
+		env = map[string]string{}
+	}
+
+	build := input.Build
+	if build == "" {
+		build = types.BUILD_HOST
+	}
+	if build == types.BUILD_CONTAINER && info.Builder == "" && containerTemplates[info.Language] != "" {
+		return nil, fmt.Errorf("the %s runtime has no builder image", input.Runtime)
+	}
+
+	return &DockerfileContext{
+		Runtime:      input.Runtime,
+		Language:     info.Language,
+		Image:        string(info.Image),
+		Tag:          info.Tag,
+		Handler:      input.Handler,
+		Architecture: architecture,
+		Platform:     types.DockerPlatform(architecture),
+		Build:        build,
+		Builder:      info.Builder,
+		Env:          env,
+		Deploy:       input.Deploy,
+	}, nil
 }
 
-func NewComposeTemplate(configPath string) *ComposeTemplate {
-	template := new(ComposeTemplate)
+// Renders the Dockerfile template of the context's runtime and build
+func renderDockerFile(input *NewDockerFileInput, context *DockerfileContext) (DockerFileWriter, error) {
+	templateName, err := DockerTemplateName(context.Runtime, context.Build)
+	if err != nil {
+		return nil, err
+	}
 
-	template.ConfigPath = configPath
-	template.Cfg = viper.New()
-	template.Cfg.AddConfigPath(configPath)
-	template.Cfg.SetConfigName("compose")
-	template.Cfg.SetConfigType("yaml")
+	return renderTemplateFile(input, templateName, "Dockerfile", context)
+}
 
-	return template
+// Renders the template with the context, looking it up in the project of the input first
+func renderTemplateFile(input *NewDockerFileInput, templateName string, fileName string, context *DockerfileContext) (DockerFileWriter, error) {
+	dockerFile, err := RenderTemplate(input.FilePath, templateName, context)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DockerFile{
+		dockerFile: dockerFile,
+		filePath:   fmt.Sprintf("%s/%s", input.FilePath, fileName),
+	}, nil
 }
 
 type DockerFileWriter interface {
 	WriteFile() error
+	// Path the Dockerfile is written to
+	Path() string
+	// Content written to Path, including the generated header
+	Content() string
 }
 
 type DockerFile struct {
@@ -101,7 +164,15 @@ type DockerFile struct {
 }
 
 func (df *DockerFile) WriteFile() error {
-	return os.WriteFile(df.filePath, []byte(df.dockerFile), 0644)
+	return os.WriteFile(df.filePath, []byte(df.Content()), 0644)
+}
+
+func (df *DockerFile) Path() string {
+	return df.filePath
+}
+
+func (df *DockerFile) Content() string {
+	return Stamp(df.dockerFile)
 }
 
 type NewDockerFileInput struct {
@@ -109,23 +180,42 @@ type NewDockerFileInput struct {
 	Runtime string
 	// Handler of the lambda function
 	Handler string
-	// Directory location the dockerfile will be written to
+	// Architecture the image is pinned to, defaults to types.DEFAULT_ARCHITECTURE
+	Architecture lambdaTypes.Architecture
+	// Directory location the dockerfile will be written to, templates in its
+	// PROJECT_TEMPLATE_DIR override the embedded ones
 	FilePath string
-	// Optional: Virtual Environment, used for Python
-	VirtualEnv pythonUtils.PythonVirtualEnvDriver
-	// Optional: Driver to modify the project's Maven POM file, used for Java
-	MavenPomDriver java.MavenPomDriver
+	// Optional: Variables set with ENV in the image
+	Env map[string]string
+	// Optional: Where the function is built, defaults to types.BUILD_HOST
+	Build types.BuildMode
+	// Optional: Location of the vendored dependencies, used for Python
+	PythonDependencies pythonUtils.PythonDependencyDriver
+	// Optional: Build tool and layout of the project, used for Java, defaults to maven
+	JavaProject *java.JavaProject
+	// Optional: Driver to modify the project's pom.xml or build.gradle, used for Java
+	JavaBuildDriver java.BuildFileDriver
+	// Optional: Render the image deployed to Lambda instead of the one of local runs
+	Deploy bool
 }
 
 func NewDockerFile(input *NewDockerFileInput) (DockerFileWriter, error) {
-	if strings.Contains(string(input.Runtime), "nodejs") {
+	info, err := types.LookupRuntime(input.Runtime)
+	if err != nil {
+		return nil, errors.New("no docker image supports given runtime")
+	}
+
+	switch info.Language {
+	case types.NodeJs:
 		return NewNodeJSDockerFile(input)
-	} else if strings.Contains(string(input.Runtime), "python") {
+	case types.Python:
 		return NewPythonDockerFile(input)
-	} else if strings.Contains(string(input.Runtime), "provided.al2") {
+	case types.Golang:
 		return NewGoDockerFile(input)
-	} else if strings.Contains(string(input.Runtime), "java") {
+	case types.Java:
 		return NewJavaDockerFile(input)
+	case types.Ruby:
+		return NewRubyDockerFile(input)
 	}
 
 	return nil, errors.New("no docker image supports given runtime")
@@ -133,107 +223,108 @@ func NewDockerFile(input *NewDockerFileInput) (DockerFileWriter, error) {
 
 // Creates a new JavaDockerFile writer ready to write a properly formatted Dockerfile for Java lambdas
 func NewJavaDockerFile(input *NewDockerFileInput) (DockerFileWriter, error) {
-	jdf := new(DockerFile)
-
-	if !input.MavenPomDriver.HasRequiredPlugins() {
-		input.MavenPomDriver.AddRequiredPlugins()
-	}
-	err := input.MavenPomDriver.WriteFile()
+	context, err := newDockerfileContext(input)
 	if err != nil {
 		return nil, err
 	}
 
-	tag, err := getDockerImageTag(lambdaTypes.Runtime(input.Runtime))
+	project := input.JavaProject
+	if project == nil {
+		project = &java.JavaProject{Dir: input.FilePath, Tool: java.BUILD_TOOL_MAVEN}
+	}
+	context.ClassesPath = project.ClassesPath()
+	context.DepsPath = project.DependencyPath()
+
+	// NOTE: the maven builder stage copies the dependencies itself
+	if context.Build == types.BUILD_CONTAINER && project.Tool == java.BUILD_TOOL_MAVEN {
+		return renderDockerFile(input, context)
+	}
+
+	if input.JavaBuildDriver == nil {
+		return nil, errors.New("creation of java dockerfile requires a build file driver")
+	}
+	if !input.JavaBuildDriver.HasRequiredPlugins() {
+		input.JavaBuildDriver.AddRequiredPlugins()
+	}
+	err = input.JavaBuildDriver.WriteFile()
 	if err != nil {
 		return nil, err
 	}
 
-	jdf.dockerFile = fmt.Sprintf(dockerFileJava, string(types.DockerImageJava), tag, input.Handler)
-	jdf.filePath = fmt.Sprintf("%s/Dockerfile", input.FilePath)
+	// NOTE: gradle builds within the builder stage with the task added to the build file
+	if context.Build == types.BUILD_CONTAINER {
+		return renderTemplateFile(input, GRADLE_CONTAINER_TEMPLATE, "Dockerfile", context)
+	}
 
-	return jdf, nil
+	return renderDockerFile(input, context)
 }
 
 // Creates a new PythonDockerFile writer ready to write a properly formatted Dockerfile for Python lambdas
 func NewPythonDockerFile(input *NewDockerFileInput) (DockerFileWriter, error) {
-	pdf := new(DockerFile)
+	context, err := newDockerfileContext(input)
+	if err != nil {
+		return nil, err
+	}
 
-	if input.VirtualEnv == nil {
-		return nil, errors.New("creation of python dockerfile requires a venv driver")
+	// NOTE: the builder stage installs the dependencies itself
+	if context.Build == types.BUILD_CONTAINER {
+		return renderDockerFile(input, context)
 	}
 
-	// NOTE: you need to be in the root workspace of the venv for this to work
-	err := input.VirtualEnv.CwdContainsVenv()
-	if err != nil {
-		return nil, err
+	if input.PythonDependencies == nil {
+		return nil, errors.New("creation of python dockerfile requires a dependency driver")
 	}
 
-	depsPath, err := input.VirtualEnv.DependencyPath()
+	depsPath, err := input.PythonDependencies.DependencyPath()
 	if err != nil {
 		return nil, err
 	}
+	context.DepsPath = depsPath
+
+	return renderDockerFile(input, context)
+}
 
-	tag, err := getDockerImageTag(lambdaTypes.Runtime(input.Runtime))
+// Creates a new GoDockerFile writer ready to write a properly formatted Dockerfile for Go lambdas
+func NewGoDockerFile(input *NewDockerFileInput) (DockerFileWriter, error) {
+	context, err := newDockerfileContext(input)
 	if err != nil {
 		return nil, err
 	}
 
-	pdf.filePath = fmt.Sprintf("%s/Dockerfile", input.FilePath)
-	pdf.dockerFile = fmt.Sprintf(
-		dockerFilePython,
-		string(types.DockerImagePython),
-		tag,
-		depsPath,
-		input.Handler,
-	)
-
-	return pdf, nil
+	return renderDockerFile(input, context)
 }
 
-// Creates a new GoDockerFile writer ready to write a properly formatted Dockerfile for Go lambdas
-func NewGoDockerFile(input *NewDockerFileInput) (DockerFileWriter, error) {
-	gdf := new(DockerFile)
+// Port delve listens on within Go debug images
+const GO_DEBUG_PORT int = 2345
 
-	tag, err := getDockerImageTag(lambdaTypes.Runtime(input.Runtime))
+// Creates a writer for Dockerfile.debug, which builds the Go bootstrap without
+// optimizations and runs it through delve listening on GO_DEBUG_PORT
+func NewGoDebugDockerFile(input *NewDockerFileInput) (DockerFileWriter, error) {
+	context, err := newDockerfileContext(input)
 	if err != nil {
 		return nil, err
 	}
+	context.DebugPort = GO_DEBUG_PORT
 
-	gdf.dockerFile = fmt.Sprintf(dockerFileGo, string(types.DockerImageGo), tag)
-	gdf.filePath = fmt.Sprintf("%s/Dockerfile", input.FilePath)
-
-	return gdf, nil
+	return renderTemplateFile(input, GO_DEBUG_TEMPLATE, "Dockerfile.debug", context)
 }
 
 // Creates a new NodeJSDockerFile writer ready to write a properly formatted Dockerfile for NodeJS lambdas
 func NewNodeJSDockerFile(input *NewDockerFileInput) (DockerFileWriter, error) {
-	ndf := new(DockerFile)
-
-	tag, err := getDockerImageTag(lambdaTypes.Runtime(input.Runtime))
+	context, err := newDockerfileContext(input)
 	if err != nil {
 		return nil, err
 	}
 
-	ndf.filePath = fmt.Sprintf("%s/Dockerfile", input.FilePath)
-	ndf.dockerFile = fmt.Sprintf(dockerFileNodeJS, string(types.DockerImageNodeJS), tag, input.Handler)
-
-	return ndf, nil
+	return renderDockerFile(input, context)
 }
 
-func getDockerImageTag(runtime lambdaTypes.Runtime) (string, error) {
-	const latestTag string = "latest"
-
-	if strings.Contains(string(runtime), "nodejs") {
-		regx := regexp.MustCompile("[0-9]+")
-		tag := regx.FindString(string(runtime))
-		return tag, nil
-	} else if strings.Contains(string(runtime), "python") {
-		return strings.Split(string(runtime), "python")[1], nil
-	} else if strings.Contains(string(runtime), "provided.al2") {
-		return latestTag, nil
-	} else if strings.Contains(string(runtime), "java") {
-		return strings.Split(string(runtime), "java")[1], nil
+// Creates a new RubyDockerFile writer ready to write a properly formatted Dockerfile for Ruby lambdas
+func NewRubyDockerFile(input *NewDockerFileInput) (DockerFileWriter, error) {
+	context, err := newDockerfileContext(input)
+	if err != nil {
+		return nil, err
 	}
 
-	return "", errors.New("no docker image supports given runtime")
+	return renderDockerFile(input, context)
 }
@@ -3,7 +3,11 @@ package templates
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/obscurelyme/jeeves/types"
+	"github.com/obscurelyme/jeeves/utils/java"
 )
 
 func readFile(tmpDir string, filename string) (string, error) {
@@ -13,33 +17,20 @@ func readFile(tmpDir string, filename string) (string, error) {
 	return string(data), err
 }
 
-type MockPythonVirtualEnv struct {
-	TmpDir            string
-	MockPythonVersion string
+type MockPythonDependencies struct {
+	TmpDir string
 }
 
-func (p *MockPythonVirtualEnv) Path() string {
-	return fmt.Sprintf("%s/venv", p.TmpDir)
-}
-func (p *MockPythonVirtualEnv) Name() (string, error) {
-	return "venv", nil
-}
-func (p *MockPythonVirtualEnv) CwdContainsVenv() error {
-	return nil
-}
-func (p *MockPythonVirtualEnv) PythonVersion() (string, error) {
-	return p.MockPythonVersion, nil
-}
-func (p *MockPythonVirtualEnv) DependencyPath() (string, error) {
-	return fmt.Sprintf("%s/venv/lib/%s/site-packages", p.TmpDir, p.MockPythonVersion), nil
+func (p *MockPythonDependencies) DependencyPath() (string, error) {
+	return fmt.Sprintf("%s/.jeeves/python", p.TmpDir), nil
 }
 
-const expectedFile string = `FROM amazon/aws-lambda-python:3.12
+const expectedFile string = `FROM --platform=linux/arm64 amazon/aws-lambda-python:3.12
 
 RUN pip3 install debugpy
 
-# Copy the dependencies from the active venv
-COPY %s/venv/lib/python3.12/site-packages ${LAMBDA_TASK_ROOT}
+# Copy the dependencies vendored for Lambda
+COPY %s/.jeeves/python ${LAMBDA_TASK_ROOT}
 # Copy source code
 COPY src/* ${LAMBDA_TASK_ROOT}
 
@@ -56,9 +47,8 @@ func TestWriteDockerFiles(t *testing.T) {
 			Runtime:  "python3.12",
 			Handler:  "main.handler",
 			FilePath: tmpDir,
-			VirtualEnv: &MockPythonVirtualEnv{
-				TmpDir:            tmpDir,
-				MockPythonVersion: "python3.12",
+			PythonDependencies: &MockPythonDependencies{
+				TmpDir: tmpDir,
 			},
 		})
 
@@ -80,8 +70,159 @@ func TestWriteDockerFiles(t *testing.T) {
 			return
 		}
 
-		if file != fmt.Sprintf(expectedFile, tmpDir) {
+		generated := ParseGenerated(file)
+		if generated.State() != GENERATED_CURRENT {
+			t.Errorf("expected a generated header, but received %s", generated.State())
+		}
+		if generated.Body != fmt.Sprintf(expectedFile, tmpDir) {
 			t.Errorf("mismatched file, \n%s\n%s", file, expectedFile)
 		}
 	})
+	t.Run("python deployed to Lambda", func(t *testing.T) {
+		for _, build := range []types.BuildMode{types.BUILD_HOST, types.BUILD_CONTAINER} {
+			dockerFile, err := NewDockerFile(&NewDockerFileInput{
+				Runtime:            "python3.12",
+				Handler:            "main.handler",
+				FilePath:           tmpDir,
+				Build:              build,
+				PythonDependencies: &MockPythonDependencies{TmpDir: tmpDir},
+				Deploy:             true,
+			})
+			if err != nil {
+				t.Errorf("expected no errors but received, \"%s\"", err.Error())
+				return
+			}
+
+			if content := dockerFile.Content(); strings.Contains(content, "debugpy") || strings.Contains(content, "bootstrap.sh") {
+				t.Errorf("expected the %s image to leave out the debugger, but received\n%s", build, content)
+			}
+		}
+	})
+	t.Run("python built within docker", func(t *testing.T) {
+		dockerFile, err := NewDockerFile(&NewDockerFileInput{
+			Runtime:  "python3.10",
+			Handler:  "main.handler",
+			FilePath: tmpDir,
+			Build:    types.BUILD_CONTAINER,
+		})
+		if err != nil {
+			t.Errorf("expected no errors but received, \"%s\"", err.Error())
+			return
+		}
+
+		for _, expected := range []string{"uv.lock* poetry.lock*", "uv export", "poetry export", "tomli"} {
+			if !strings.Contains(dockerFile.Content(), expected) {
+				t.Errorf("expected the Dockerfile to contain %s, but received\n%s", expected, dockerFile.Content())
+			}
+		}
+	})
+	t.Run("ruby", func(t *testing.T) {
+		dockerFile, err := NewDockerFile(&NewDockerFileInput{
+			Runtime:      "ruby3.3",
+			Handler:      "handler.handler",
+			Architecture: "x86_64",
+			FilePath:     tmpDir,
+		})
+		if err != nil {
+			t.Errorf("expected no errors but received, \"%s\"", err.Error())
+			return
+		}
+
+		err = dockerFile.WriteFile()
+		if err != nil {
+			t.Errorf("expected no errors but received, \"%s\"", err.Error())
+			return
+		}
+
+		file, err := readFile(tmpDir, "Dockerfile")
+		if err != nil {
+			t.Errorf("expected no errors but received, \"%s\"", err.Error())
+			return
+		}
+
+		file = ParseGenerated(file).Body
+		if !strings.HasPrefix(file, "FROM --platform=linux/amd64 amazon/aws-lambda-ruby:3.3") || !strings.HasSuffix(file, `CMD [ "handler.handler" ]`) {
+			t.Errorf("unexpected ruby Dockerfile, \n%s", file)
+		}
+		// NOTE: projects from faas init have no Gemfile.lock and local files such as .env stay out of the image
+		if !strings.Contains(file, "COPY Gemfile* ") || strings.Contains(file, "COPY . ") {
+			t.Errorf("unexpected ruby Dockerfile, \n%s", file)
+		}
+	})
+
+	t.Run("unknown runtime", func(t *testing.T) {
+		_, err := NewDockerFile(&NewDockerFileInput{Runtime: "cobol1.0", FilePath: tmpDir})
+		if err == nil {
+			t.Errorf("expected an error for an unknown runtime")
+		}
+	})
+}
+
+type MockJavaBuildDriver struct {
+	Written bool
+}
+
+func (d *MockJavaBuildDriver) HasRequiredPlugins() bool {
+	return false
+}
+func (d *MockJavaBuildDriver) AddRequiredPlugins() {}
+func (d *MockJavaBuildDriver) WriteFile() error {
+	d.Written = true
+	return nil
+}
+
+func TestJavaDockerFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	gradle := &java.JavaProject{Dir: tmpDir, Tool: java.BUILD_TOOL_GRADLE}
+
+	t.Run("should copy the gradle layout", func(t *testing.T) {
+		driver := &MockJavaBuildDriver{}
+		dockerFile, err := NewDockerFile(&NewDockerFileInput{
+			Runtime:         "java21",
+			Handler:         "com.example.Handler::handleRequest",
+			FilePath:        tmpDir,
+			JavaProject:     gradle,
+			JavaBuildDriver: driver,
+		})
+		if err != nil {
+			t.Errorf("expected no errors but received, \"%s\"", err.Error())
+			return
+		}
+
+		body := ParseGenerated(dockerFile.Content()).Body
+		if !strings.Contains(body, "COPY build/lambda/classes ${LAMBDA_TASK_ROOT}\nCOPY build/lambda/dependency/* ${LAMBDA_TASK_ROOT}/lib/") {
+			t.Errorf("unexpected java Dockerfile, \n%s", body)
+		}
+		if !driver.Written {
+			t.Errorf("expected the build file to be written")
+		}
+	})
+
+	t.Run("should build gradle projects with the wrapper in a builder stage", func(t *testing.T) {
+		dockerFile, err := NewDockerFile(&NewDockerFileInput{
+			Runtime:         "java17",
+			Handler:         "com.example.Handler::handleRequest",
+			FilePath:        tmpDir,
+			Build:           types.BUILD_CONTAINER,
+			JavaProject:     gradle,
+			JavaBuildDriver: &MockJavaBuildDriver{},
+		})
+		if err != nil {
+			t.Errorf("expected no errors but received, \"%s\"", err.Error())
+			return
+		}
+
+		for _, expected := range []string{"FROM --platform=linux/arm64 maven:3-amazoncorretto-17 AS build", "./gradlew --no-daemon -q -x test jeevesLambdaLayout", "COPY --from=build /build/build/lambda/classes ${LAMBDA_TASK_ROOT}"} {
+			if !strings.Contains(dockerFile.Content(), expected) {
+				t.Errorf("expected the Dockerfile to contain %s, but received\n%s", expected, dockerFile.Content())
+			}
+		}
+	})
+
+	t.Run("should require a build file driver for host builds", func(t *testing.T) {
+		_, err := NewDockerFile(&NewDockerFileInput{Runtime: "java21", FilePath: tmpDir})
+		if err == nil {
+			t.Errorf("expected an error without a build file driver")
+		}
+	})
 }
@@ -0,0 +1,207 @@
+package types
+
+import (
+	_ "embed"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/goccy/go-yaml"
+)
+
+//go:embed runtimes.yaml
+var runtimesYaml []byte
+
+// How long before its deprecation date a runtime starts producing warnings
+const DEPRECATION_WARNING_PERIOD time.Duration = 180 * 24 * time.Hour
+
+// Date format of the deprecation dates within the catalog
+const DEPRECATION_DATE_FORMAT string = "2006-01-02"
+
+// An entry of the runtime catalog
+type RuntimeInfo struct {
+	Runtime  lambdaTypes.Runtime `yaml:"runtime"`
+	Language LambdaLanguage      `yaml:"language"`
+	// Handler convention of the language's project template
+	Handler string `yaml:"handler"`
+	// Base image and tag of the runtime for local runs
+	Image         DockerImage                `yaml:"image"`
+	Tag           string                     `yaml:"tag"`
+	Architectures []lambdaTypes.Architecture `yaml:"architectures"`
+	// Image building the function within docker with BUILD_CONTAINER, IE: node:22
+	Builder string `yaml:"builder"`
+	// glibc of the runtime's Amazon Linux, the newest manylinux wheels run on, IE: 2.34
+	Glibc string `yaml:"glibc"`
+	// Key of the example code in the examples bucket deployed by faas create, IE: java-function.jar
+	Example string `yaml:"example"`
+	// Added to the lambda service of the compose file faas start generates
+	Compose RuntimeCompose `yaml:"compose"`
+	// Date AWS stops applying security patches, IE: 2026-04-30
+	Deprecation string `yaml:"deprecation"`
+}
+
+// Compose settings of a runtime, IE: flushing python's output for docker logs
+type RuntimeCompose struct {
+	Environment map[string]string `yaml:"environment"`
+	Ports       []string          `yaml:"ports"`
+	Volumes     []string          `yaml:"volumes"`
+}
+
+var catalog []RuntimeInfo
+
+func init() {
+	err := yaml.Unmarshal(runtimesYaml, &catalog)
+	if err != nil {
+		panic(fmt.Sprintf("invalid runtime catalog: %s", err.Error()))
+	}
+
+	RuntimeSelectionOptions = selectionOptions(time.Now())
+}
+
+// Lists every runtime of the catalog, including deprecated ones
+func Runtimes() []RuntimeInfo {
+	return catalog
+}
+
+// Finds the catalog entry of the runtime, IE: nodejs20.x
+func LookupRuntime(runtime string) (*RuntimeInfo, error) {
+	for i := range catalog {
+		if string(catalog[i].Runtime) == runtime {
+			return &catalog[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported runtime \"%s\"", runtime)
+}
+
+func (r *RuntimeInfo) DeprecationDate() (time.Time, bool) {
+	date, err := time.Parse(DEPRECATION_DATE_FORMAT, r.Deprecation)
+	return date, err == nil
+}
+
+func (r *RuntimeInfo) Deprecated(now time.Time) bool {
+	date, ok := r.DeprecationDate()
+	return ok && !now.Before(date)
+}
+
+// Returns a warning once the runtime is deprecated or within
+// DEPRECATION_WARNING_PERIOD of its deprecation date, an empty string otherwise.
+func (r *RuntimeInfo) DeprecationWarning(now time.Time) string {
+	date, ok := r.DeprecationDate()
+	if !ok {
+		return ""
+	}
+
+	if !now.Before(date) {
+		return fmt.Sprintf("%s was deprecated on %s and no longer receives security patches, please upgrade", r.Runtime, r.Deprecation)
+	}
+
+	if date.Sub(now) <= DEPRECATION_WARNING_PERIOD {
+		return fmt.Sprintf("%s will be deprecated on %s, please plan an upgrade", r.Runtime, r.Deprecation)
+	}
+
+	return ""
+}
+
+// Deprecation warning of the runtime, empty for unknown runtimes
+func RuntimeDeprecationWarning(runtime string) string {
+	info, err := LookupRuntime(runtime)
+	if err != nil {
+		return ""
+	}
+
+	return info.DeprecationWarning(time.Now())
+}
+
+// Runtimes of the selection options with example code, which faas create deploys
+func CreateSelectionOptions() []LambdaRuntime {
+	options := []LambdaRuntime{}
+	for _, option := range RuntimeSelectionOptions {
+		if option.Example != "" {
+			options = append(options, option)
+		}
+	}
+
+	return options
+}
+
+// Every runtime which is not deprecated yet may be selected for new FaaS resources
+func selectionOptions(now time.Time) []LambdaRuntime {
+	options := []LambdaRuntime{}
+
+	for _, info := range catalog {
+		if info.Deprecated(now) {
+			continue
+		}
+
+		options = append(options, info.Option())
+	}
+
+	return options
+}
+
+// Selection option of the runtime, deprecated ones included
+func (r *RuntimeInfo) Option() LambdaRuntime {
+	return LambdaRuntime{
+		AWSRuntime:   r.Runtime,
+		Language:     r.Language,
+		Handler:      r.Handler,
+		Example:      r.Example,
+		TemplateRepo: fmt.Sprintf("%s-lambda", r.Language),
+	}
+}
+
+// Architecture of FaaS resources when none is selected
+const DEFAULT_ARCHITECTURE lambdaTypes.Architecture = lambdaTypes.ArchitectureArm64
+
+// Parses a Lambda architecture, accepting the docker names aarch64 and amd64 as well
+func ParseArchitecture(value string) (lambdaTypes.Architecture, error) {
+	switch strings.ToLower(value) {
+	case "arm64", "aarch64":
+		return lambdaTypes.ArchitectureArm64, nil
+	case "x86_64", "amd64", "x86-64":
+		return lambdaTypes.ArchitectureX8664, nil
+	}
+
+	return "", fmt.Errorf("invalid architecture \"%s\", expected arm64 or x86_64", value)
+}
+
+// Where the function is built before copying it into the Lambda image
+type BuildMode string
+
+// Built with the toolchain of the host, IE: npm run build before faas start
+const BUILD_HOST BuildMode = "host"
+
+// Built within a builder stage of the Dockerfile for the function's architecture
+const BUILD_CONTAINER BuildMode = "container"
+
+// Parses function.build of faas.yaml, defaults to BUILD_HOST
+func ParseBuildMode(value string) (BuildMode, error) {
+	switch BuildMode(strings.ToLower(value)) {
+	case "", BUILD_HOST:
+		return BUILD_HOST, nil
+	case BUILD_CONTAINER:
+		return BUILD_CONTAINER, nil
+	}
+
+	return "", fmt.Errorf("invalid build \"%s\", expected host or container", value)
+}
+
+// Docker platform of the Lambda architecture, IE: linux/arm64. Defaults to DEFAULT_ARCHITECTURE.
+func DockerPlatform(architecture lambdaTypes.Architecture) string {
+	if architecture == "" {
+		architecture = DEFAULT_ARCHITECTURE
+	}
+
+	if architecture == lambdaTypes.ArchitectureX8664 {
+		return "linux/amd64"
+	}
+
+	return "linux/arm64"
+}
+
+func (r *RuntimeInfo) SupportsArchitecture(architecture lambdaTypes.Architecture) bool {
+	return slices.Contains(r.Architectures, architecture)
+}
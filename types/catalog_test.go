@@ -0,0 +1,143 @@
+package types
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLookupRuntime(t *testing.T) {
+	t.Run("should find the base image and tag of a runtime", func(t *testing.T) {
+		info, err := LookupRuntime("python3.12")
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if info.Language != Python || info.Image != "amazon/aws-lambda-python" || info.Tag != "3.12" {
+			t.Errorf("unexpected catalog entry %+v", info)
+		}
+
+		if info.Deprecation != "2028-10-31" {
+			t.Errorf("expected deprecation date 2028-10-31, but received \"%s\"", info.Deprecation)
+		}
+	})
+
+	t.Run("should fail for unknown runtimes", func(t *testing.T) {
+		_, err := LookupRuntime("cobol1.0")
+		if err == nil {
+			t.Errorf("expected an error for an unknown runtime")
+		}
+	})
+
+	t.Run("should describe every runtime completely", func(t *testing.T) {
+		for _, info := range Runtimes() {
+			if info.Language == "" || info.Handler == "" || info.Image == "" || info.Tag == "" || len(info.Architectures) == 0 {
+				t.Errorf("incomplete catalog entry %+v", info)
+			}
+			if _, ok := info.DeprecationDate(); !ok {
+				t.Errorf("invalid deprecation date \"%s\" for %s", info.Deprecation, info.Runtime)
+			}
+		}
+	})
+}
+
+func TestDeprecationWarning(t *testing.T) {
+	info := &RuntimeInfo{Runtime: "nodejs20.x", Deprecation: "2026-04-30"}
+
+	t.Run("should not warn long before the deprecation date", func(t *testing.T) {
+		warning := info.DeprecationWarning(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		if warning != "" {
+			t.Errorf("expected no warning, but received \"%s\"", warning)
+		}
+	})
+
+	t.Run("should warn when nearing the deprecation date", func(t *testing.T) {
+		warning := info.DeprecationWarning(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+		if !strings.Contains(warning, "will be deprecated on 2026-04-30") {
+			t.Errorf("expected an upcoming deprecation warning, but received \"%s\"", warning)
+		}
+	})
+
+	t.Run("should warn once deprecated", func(t *testing.T) {
+		warning := info.DeprecationWarning(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC))
+		if !strings.Contains(warning, "was deprecated on 2026-04-30") {
+			t.Errorf("expected a deprecation warning, but received \"%s\"", warning)
+		}
+	})
+}
+
+func TestSelectionOptions(t *testing.T) {
+	t.Run("should only offer runtimes which are not deprecated", func(t *testing.T) {
+		options := selectionOptions(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC))
+
+		for _, option := range options {
+			if option.AWSRuntime == "nodejs20.x" || option.AWSRuntime == "nodejs18.x" {
+				t.Errorf("expected %s to be excluded", option.AWSRuntime)
+			}
+		}
+
+		if len(options) == 0 || options[0].AWSRuntime != "nodejs22.x" {
+			t.Errorf("expected nodejs22.x to be the first option")
+		}
+	})
+
+	t.Run("should only create runtimes with example code", func(t *testing.T) {
+		for _, option := range CreateSelectionOptions() {
+			if option.Language == Ruby {
+				t.Errorf("expected %s to be excluded", option.AWSRuntime)
+			}
+			if option.Language == Java && option.Example != "java-function.jar" {
+				t.Errorf("expected the java example to be a jar, but received %s", option.Example)
+			}
+		}
+	})
+}
+
+func TestParseArchitecture(t *testing.T) {
+	t.Run("should accept lambda and docker names", func(t *testing.T) {
+		for value, expected := range map[string]string{"arm64": "arm64", "aarch64": "arm64", "x86_64": "x86_64", "amd64": "x86_64"} {
+			architecture, err := ParseArchitecture(value)
+			if err != nil {
+				t.Errorf("expected no errors, but received \"%s\"", err.Error())
+				continue
+			}
+			if string(architecture) != expected {
+				t.Errorf("expected %s for %s, but received %s", expected, value, architecture)
+			}
+			if platform := DockerPlatform(architecture); platform != "linux/"+map[string]string{"arm64": "arm64", "x86_64": "amd64"}[expected] {
+				t.Errorf("unexpected platform %s for %s", platform, architecture)
+			}
+		}
+	})
+}
+
+func TestParseBuildMode(t *testing.T) {
+	t.Run("should default to host builds", func(t *testing.T) {
+		for value, expected := range map[string]BuildMode{"": BUILD_HOST, "host": BUILD_HOST, "Container": BUILD_CONTAINER} {
+			build, err := ParseBuildMode(value)
+			if err != nil {
+				t.Errorf("expected no errors, but received \"%s\"", err.Error())
+				continue
+			}
+			if build != expected {
+				t.Errorf("expected %s for \"%s\", but received %s", expected, value, build)
+			}
+		}
+	})
+
+	t.Run("should reject unknown builds", func(t *testing.T) {
+		_, err := ParseBuildMode("docker")
+		if err == nil {
+			t.Errorf("expected an error for an unknown build")
+		}
+	})
+
+	t.Run("should have a builder for every runtime but ruby", func(t *testing.T) {
+		for _, info := range Runtimes() {
+			if (info.Builder == "") != (info.Language == Ruby) {
+				t.Errorf("unexpected builder \"%s\" for %s", info.Builder, info.Runtime)
+			}
+		}
+	})
+}
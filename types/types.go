@@ -1,8 +1,6 @@
 package types
 
 import (
-	"fmt"
-
 	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
 )
 
@@ -12,6 +10,7 @@ const NodeJs LambdaLanguage = "nodejs"
 const Golang LambdaLanguage = "golang"
 const Java LambdaLanguage = "java"
 const Python LambdaLanguage = "python"
+const Ruby LambdaLanguage = "ruby"
 
 type LambdaRuntime struct {
 	// Internal AWS runtime for the lambda function
@@ -25,40 +24,16 @@ type LambdaRuntime struct {
 	TemplateRepo string
 }
 
-var RuntimeSelectionOptions []LambdaRuntime = []LambdaRuntime{
-	{
-		AWSRuntime:   lambdaTypes.RuntimeNodejs20x,
-		Language:     NodeJs,
-		Handler:      "dist/index.handler",
-		Example:      fmt.Sprintf("%s-function.zip", NodeJs),
-		TemplateRepo: fmt.Sprintf("%s-lambda", NodeJs),
-	},
-	{
-		AWSRuntime:   lambdaTypes.RuntimeProvidedal2023,
-		Language:     Golang,
-		Handler:      "main",
-		Example:      fmt.Sprintf("%s-function.zip", Golang),
-		TemplateRepo: fmt.Sprintf("%s-lambda", Golang),
-	},
-	{
-		AWSRuntime:   lambdaTypes.RuntimeJava21,
-		Language:     Java,
-		Handler:      "com.example.app.Handler::handleRequest",
-		Example:      fmt.Sprintf("%s-function.jar", Java),
-		TemplateRepo: fmt.Sprintf("%s-lambda", Java),
-	},
-	{
-		AWSRuntime:   lambdaTypes.RuntimePython310,
-		Language:     Python,
-		Handler:      "handler",
-		Example:      fmt.Sprintf("%s-function.zip", Python),
-		TemplateRepo: fmt.Sprintf("%s-lambda", Python),
-	},
-}
+// Runtimes which may be selected for new FaaS resources, built from the runtime catalog
+var RuntimeSelectionOptions []LambdaRuntime
 
 type CreateFaaSResourceInput struct {
 	FunctionName string
 	Runtime      *LambdaRuntime
+	// Tags applied to the function and its role
+	Tags map[string]string
+	// Instruction set of the function, defaults to DEFAULT_ARCHITECTURE
+	Architecture lambdaTypes.Architecture
 }
 
 // Payload to send when provisioning a new template repository for a new FaaS resource
@@ -69,6 +44,8 @@ type Payload struct {
 	RepositoryName        string `json:"repositoryName"`
 	RepositoryDescription string `json:"repositoryDescription"`
 	Visibility            string `json:"visibility"`
+	// Standard tags of the FaaS resource, applied to the repository as topics
+	Tags map[string]string `json:"tags,omitempty"`
 }
 
 // Payload to send when deleting a repository after deleting an FaaS resource
@@ -77,11 +54,5 @@ type DeleteRepositoryPayload struct {
 	RepositoryName  string `json:"repositoryName"`
 }
 
+// Base image of a runtime for local runs, IE: amazon/aws-lambda-nodejs
 type DockerImage string
-
-const (
-	DockerImageNodeJS DockerImage = "amazon/aws-lambda-nodejs"
-	DockerImageJava   DockerImage = "amazon/aws-lambda-java"
-	DockerImagePython DockerImage = "amazon/aws-lambda-python"
-	DockerImageGo     DockerImage = "amazon/aws-lambda-go"
-)
@@ -0,0 +1,89 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A file or directory to add to an archive
+type Entry struct {
+	// Path of the file or directory on disk
+	Source string
+	// Path within the archive, directories keep their contents below it
+	Target string
+}
+
+// Zips the entries in memory. Directories are walked recursively, file modes
+// are kept so executables such as the Go "bootstrap" stay executable.
+func Zip(entries []Entry) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+
+	for _, entry := range entries {
+		err := filepath.WalkDir(entry.Source, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			relative, err := filepath.Rel(entry.Source, path)
+			if err != nil {
+				return err
+			}
+
+			name := filepath.Join(entry.Target, relative)
+			if relative == "." && entry.Target == "" {
+				// NOTE: a single file without a target keeps its own name
+				name = filepath.Base(path)
+			}
+
+			return addFile(writer, path, strings.TrimPrefix(filepath.ToSlash(name), "/"))
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func addFile(writer *zip.Writer, path string, name string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	w, err := writer.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(w, file)
+	return err
+}
@@ -0,0 +1,43 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestZip(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "dist", "lib"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "dist", "index.js"), []byte("exports.handler = () => {}"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "dist", "lib", "util.js"), []byte(""), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "bootstrap"), []byte("#!/bin/sh"), 0755)
+
+	data, err := Zip([]Entry{
+		{Source: filepath.Join(tmpDir, "dist"), Target: "dist"},
+		{Source: filepath.Join(tmpDir, "bootstrap")},
+	})
+	if err != nil {
+		t.Fatalf("expected no errors, but received \"%s\"", err.Error())
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("expected a valid zip, but received \"%s\"", err.Error())
+	}
+
+	names := []string{}
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+		if file.Name == "bootstrap" && file.Mode()&0100 == 0 {
+			t.Errorf("expected bootstrap to stay executable")
+		}
+	}
+
+	if !slices.Equal(names, []string{"dist/index.js", "dist/lib/util.js", "bootstrap"}) {
+		t.Errorf("unexpected archive contents %v", names)
+	}
+}
@@ -27,11 +27,28 @@ type JeevesSSO struct {
 	Start string `yaml:"Start"`
 }
 
+type JeevesRepository struct {
+	// Where repositories for new FaaS resources are provisioned: github, gitlab, lambda or none
+	Provider string `yaml:"Provider"`
+	// User, organization or group owning the repositories
+	Owner string `yaml:"Owner"`
+	// Visibility of new repositories, IE: private, internal or public
+	Visibility string `yaml:"Visibility"`
+	// Owner of the runtime template repositories
+	TemplateOwner string `yaml:"TemplateOwner"`
+	// Optional: API token, defaults to the GITHUB_TOKEN or GITLAB_TOKEN environment variables
+	Token string `yaml:"Token"`
+	// Optional: API url, IE: for GitHub Enterprise or a self-hosted GitLab
+	Url string `yaml:"Url"`
+}
+
 type JeevesConfig struct {
 	// Jeeves AI configuration
 	AI JeevesAI `yaml:"AI"`
 	// Jeeves SSO configuration
 	SSO JeevesSSO `yaml:"SSO"`
+	// Jeeves repository provisioning configuration
+	Repository JeevesRepository `yaml:"Repository"`
 }
 
 type YamlConfigFile struct {
@@ -91,7 +108,7 @@ func LoadAWSConfig() error {
 
 	err = AWSConfig.ReadInConfig()
 	if err != nil {
-		fmt.Println("Could not read .aws/config file, please run \"jeeves login\" first")
+		fmt.Fprintln(os.Stderr, "Could not read .aws/config file, please run \"jeeves login\" first")
 		return err
 	}
 
@@ -0,0 +1,88 @@
+package java
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Gradle build file of the Groovy DSL
+const GRADLE_BUILD_FILE string = "build.gradle"
+
+// Gradle build file of the Kotlin DSL
+const GRADLE_KOTLIN_BUILD_FILE string = "build.gradle.kts"
+
+// Required task in order to lay out the classes and dependencies for the Docker container
+const REQUIRED_GRADLE_TASK string = "jeevesLambdaLayout"
+
+// NOTE: sourceSets.main.output carries the compile tasks, so the task builds the classes itself
+const gradleTask string = `
+// Required task for Jeeves - jeevesLambdaLayout
+tasks.register('jeevesLambdaLayout', Sync) {
+    into layout.buildDirectory.dir('lambda')
+    into('classes') {
+        from sourceSets.main.output
+    }
+    into('dependency') {
+        from configurations.runtimeClasspath
+    }
+}
+`
+
+const gradleKotlinTask string = `
+// Required task for Jeeves - jeevesLambdaLayout
+tasks.register<Sync>("jeevesLambdaLayout") {
+    into(layout.buildDirectory.dir("lambda"))
+    into("classes") {
+        from(sourceSets["main"].output)
+    }
+    into("dependency") {
+        from(configurations.runtimeClasspath)
+    }
+}
+`
+
+// Plugin ids of the shadow plugin, the original and its successor
+var shadowPlugins = []string{"com.github.johnrengelman.shadow", "com.gradleup.shadow"}
+
+type GradleBuildFileDriver struct {
+	content  string
+	filePath string
+}
+
+func (gbd *GradleBuildFileDriver) WriteFile() error {
+	return os.WriteFile(gbd.filePath, []byte(gbd.content), 0644)
+}
+
+// Checks if the build file registers the required task, REQUIRED_GRADLE_TASK
+func (gbd *GradleBuildFileDriver) HasRequiredPlugins() bool {
+	return strings.Contains(gbd.content, REQUIRED_GRADLE_TASK)
+}
+
+func (gbd *GradleBuildFileDriver) AddRequiredPlugins() {
+	task := gradleTask
+	if filepath.Base(gbd.filePath) == GRADLE_KOTLIN_BUILD_FILE {
+		task = gradleKotlinTask
+	}
+
+	gbd.content = strings.TrimRight(gbd.content, "\n") + "\n" + task
+}
+
+// Reads the build file of the gradle project, the Kotlin DSL takes precedence
+func NewGradle(configPath string) (*GradleBuildFileDriver, error) {
+	for _, name := range []string{GRADLE_KOTLIN_BUILD_FILE, GRADLE_BUILD_FILE} {
+		filePath := filepath.Join(configPath, name)
+		data, err := os.ReadFile(filePath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &GradleBuildFileDriver{content: string(data), filePath: filePath}, nil
+	}
+
+	return nil, fmt.Errorf("no %s or %s found in %s", GRADLE_BUILD_FILE, GRADLE_KOTLIN_BUILD_FILE, configPath)
+}
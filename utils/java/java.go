@@ -47,7 +47,8 @@ var plugins []pom.Plugin = []pom.Plugin{{
 	},
 }}
 
-type MavenPomDriver interface {
+// Adds what jeeves requires to the build file of a java project, IE: pom.xml
+type BuildFileDriver interface {
 	HasRequiredPlugins() bool
 	AddRequiredPlugins()
 	WriteFile() error
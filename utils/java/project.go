@@ -0,0 +1,154 @@
+package java
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Tool building a java project, chosen by the build file of the project
+type BuildTool string
+
+const (
+	// pom.xml
+	BUILD_TOOL_MAVEN BuildTool = "maven"
+	// build.gradle or build.gradle.kts
+	BUILD_TOOL_GRADLE BuildTool = "gradle"
+)
+
+type JavaProject struct {
+	Dir  string
+	Tool BuildTool
+	// Build file of the tool, IE: pom.xml or build.gradle.kts, empty when the project has none
+	BuildFile string
+	// Applies the gradle shadow plugin, deployed as its shadow jar
+	Shadow bool
+}
+
+// Detects the build tool of the java project within the directory. Maven takes
+// precedence when both build files exist, projects without either default to maven.
+func DetectProject(dir string) (*JavaProject, error) {
+	project := &JavaProject{Dir: dir, Tool: BUILD_TOOL_MAVEN}
+
+	for _, name := range []string{"pom.xml", GRADLE_KOTLIN_BUILD_FILE, GRADLE_BUILD_FILE} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		project.BuildFile = name
+		if name != "pom.xml" {
+			project.Tool = BUILD_TOOL_GRADLE
+			project.Shadow = slices.ContainsFunc(shadowPlugins, func(plugin string) bool {
+				return strings.Contains(string(data), plugin)
+			})
+		}
+		break
+	}
+
+	return project, nil
+}
+
+// Compiled classes and resources, relative to the project
+func (p *JavaProject) ClassesPath() string {
+	if p.Tool == BUILD_TOOL_GRADLE {
+		return "build/lambda/classes"
+	}
+
+	return "target/classes"
+}
+
+// Runtime dependencies, relative to the project
+func (p *JavaProject) DependencyPath() string {
+	if p.Tool == BUILD_TOOL_GRADLE {
+		return "build/lambda/dependency"
+	}
+
+	return "target/dependency"
+}
+
+// The gradle wrapper of the project, gradle of the PATH without one
+func (p *JavaProject) Gradle() string {
+	if p.HasWrapper() {
+		return "./gradlew"
+	}
+
+	return "gradle"
+}
+
+func (p *JavaProject) HasWrapper() bool {
+	_, err := os.Stat(filepath.Join(p.Dir, "gradlew"))
+	return err == nil
+}
+
+// Builds the classes and dependencies into ClassesPath and DependencyPath,
+// and the shadow jar of projects applying the shadow plugin
+func (p *JavaProject) BuildCommand() string {
+	if p.Tool != BUILD_TOOL_GRADLE {
+		return "mvn package dependency:copy-dependencies -DincludeScope=runtime"
+	}
+
+	if p.Shadow {
+		return fmt.Sprintf("%s %s shadowJar", p.Gradle(), REQUIRED_GRADLE_TASK)
+	}
+	return fmt.Sprintf("%s %s", p.Gradle(), REQUIRED_GRADLE_TASK)
+}
+
+// Compiles the classes into ClassesPath, IE: after a change of the sources
+func (p *JavaProject) CompileCommand() string {
+	if p.Tool != BUILD_TOOL_GRADLE {
+		return "mvn -q compile"
+	}
+
+	return fmt.Sprintf("%s -q %s", p.Gradle(), REQUIRED_GRADLE_TASK)
+}
+
+// Shadow jar built by the shadow plugin, IE: build/libs/handler-1.0-all.jar
+func (p *JavaProject) ShadowJar() (string, error) {
+	jars, err := filepath.Glob(filepath.Join(p.Dir, "build", "libs", "*-all.jar"))
+	if err != nil {
+		return "", err
+	}
+	if len(jars) == 0 {
+		return "", fmt.Errorf("no shadow jar found in %s, run \"%s shadowJar\" first", filepath.Join(p.Dir, "build", "libs"), p.Gradle())
+	}
+
+	// NOTE: older versions may be left behind, the newest jar was built last
+	slices.SortFunc(jars, func(a string, b string) int {
+		return modTime(b).Compare(modTime(a))
+	})
+	return jars[0], nil
+}
+
+// Driver adding what jeeves requires to the project's build file
+func (p *JavaProject) BuildFileDriver() (BuildFileDriver, error) {
+	// NOTE: keep the driver nil on errors instead of an interface holding a nil pointer
+	if p.Tool == BUILD_TOOL_GRADLE {
+		driver, err := NewGradle(p.Dir)
+		if err != nil {
+			return nil, err
+		}
+		return driver, nil
+	}
+
+	driver, err := New(p.Dir)
+	if err != nil {
+		return nil, err
+	}
+	return driver, nil
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
@@ -0,0 +1,115 @@
+package java
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectProject(t *testing.T) {
+	cases := []struct {
+		name      string
+		files     map[string]string
+		tool      BuildTool
+		buildFile string
+		shadow    bool
+	}{
+		{"maven", map[string]string{"pom.xml": "<project/>", "build.gradle": ""}, BUILD_TOOL_MAVEN, "pom.xml", false},
+		{"gradle", map[string]string{"build.gradle": "plugins { id 'java' }"}, BUILD_TOOL_GRADLE, GRADLE_BUILD_FILE, false},
+		{"gradle kotlin shadow", map[string]string{"build.gradle.kts": "plugins {\n    java\n    id(\"com.gradleup.shadow\") version \"8.3.5\"\n}"}, BUILD_TOOL_GRADLE, GRADLE_KOTLIN_BUILD_FILE, true},
+		{"nothing", map[string]string{}, BUILD_TOOL_MAVEN, "", false},
+	}
+
+	for _, c := range cases {
+		t.Run("should detect "+c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range c.files {
+				os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+			}
+
+			project, err := DetectProject(dir)
+			if err != nil {
+				t.Errorf("expected no errors, but received \"%s\"", err.Error())
+				return
+			}
+			if project.Tool != c.tool || project.BuildFile != c.buildFile || project.Shadow != c.shadow {
+				t.Errorf("expected %s %s %t, but received %+v", c.tool, c.buildFile, c.shadow, project)
+			}
+		})
+	}
+
+	t.Run("should lay out gradle projects under build/lambda", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "gradlew"), []byte(""), 0755)
+
+		project := &JavaProject{Dir: dir, Tool: BUILD_TOOL_GRADLE, Shadow: true}
+		if project.ClassesPath() != "build/lambda/classes" || project.DependencyPath() != "build/lambda/dependency" {
+			t.Errorf("unexpected layout %s %s", project.ClassesPath(), project.DependencyPath())
+		}
+		if command := project.BuildCommand(); command != "./gradlew jeevesLambdaLayout shadowJar" {
+			t.Errorf("unexpected build command %s", command)
+		}
+	})
+
+	t.Run("should find the newest shadow jar", func(t *testing.T) {
+		dir := t.TempDir()
+		libs := filepath.Join(dir, "build", "libs")
+		os.MkdirAll(libs, 0755)
+		os.WriteFile(filepath.Join(libs, "handler-1.0.jar"), []byte("jar"), 0644)
+		os.WriteFile(filepath.Join(libs, "handler-1.0-all.jar"), []byte("jar"), 0644)
+		os.WriteFile(filepath.Join(libs, "handler-1.1-all.jar"), []byte("jar"), 0644)
+		old := time.Now().Add(-time.Hour)
+		os.Chtimes(filepath.Join(libs, "handler-1.0-all.jar"), old, old)
+
+		project := &JavaProject{Dir: dir, Tool: BUILD_TOOL_GRADLE, Shadow: true}
+		jar, err := project.ShadowJar()
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if filepath.Base(jar) != "handler-1.1-all.jar" {
+			t.Errorf("expected handler-1.1-all.jar, but received %s", jar)
+		}
+	})
+}
+
+func TestGradleBuildFile(t *testing.T) {
+	cases := []struct {
+		buildFile string
+		expected  string
+	}{
+		{GRADLE_BUILD_FILE, "tasks.register('jeevesLambdaLayout', Sync)"},
+		{GRADLE_KOTLIN_BUILD_FILE, "tasks.register<Sync>(\"jeevesLambdaLayout\")"},
+	}
+
+	for _, c := range cases {
+		t.Run("Adds the required task to "+c.buildFile, func(t *testing.T) {
+			dir := t.TempDir()
+			os.WriteFile(filepath.Join(dir, c.buildFile), []byte("plugins {\n    id 'java'\n}\n"), 0644)
+
+			driver, err := NewGradle(dir)
+			if err != nil {
+				t.Errorf("expected no errors, but received \"%s\"", err.Error())
+				return
+			}
+			if driver.HasRequiredPlugins() {
+				t.Errorf("expected the task to be missing")
+			}
+
+			driver.AddRequiredPlugins()
+			driver.WriteFile()
+
+			data, _ := os.ReadFile(filepath.Join(dir, c.buildFile))
+			if !strings.HasPrefix(string(data), "plugins {") || !strings.Contains(string(data), c.expected) {
+				t.Errorf("unexpected build file\n%s", data)
+			}
+
+			driver, _ = NewGradle(dir)
+			if !driver.HasRequiredPlugins() {
+				t.Errorf("expected the task to be added once")
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package python
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/obscurelyme/jeeves/types"
+)
+
+// Oldest manylinux tag every Lambda python runtime supports
+const MANYLINUX_LEGACY_TAG string = "manylinux2014"
+
+// Directories of installed packages which are never imported on Lambda
+var strippedDirs = []string{"__pycache__", "tests", "test"}
+
+// The Lambda runtime python dependencies are installed for
+type LambdaPlatform struct {
+	// IE: 3.12
+	PythonVersion string
+	// Machine of the wheels, IE: aarch64 or x86_64
+	Machine string
+	// Newest glibc of the runtime's Amazon Linux, IE: 2.34
+	Glibc string
+	// Lambda image installing requirements without wheels, IE: amazon/aws-lambda-python:3.12
+	Image string
+	// IE: linux/arm64
+	DockerPlatform string
+}
+
+// What was installed by PythonProject.Vendor
+type VendorReport struct {
+	// Top level packages shipping shared objects, IE: numpy or pydantic_core
+	Native []string
+	// Some requirement has no wheel for the platform, everything was installed within the Lambda image
+	Container bool
+}
+
+// Looks up the python version and glibc of the runtime, IE: python3.12, and the
+// machine of the architecture. Defaults to types.DEFAULT_ARCHITECTURE.
+func NewLambdaPlatform(runtime string, architecture lambdaTypes.Architecture) (*LambdaPlatform, error) {
+	info, err := types.LookupRuntime(runtime)
+	if err != nil {
+		return nil, err
+	}
+	if info.Language != types.Python {
+		return nil, fmt.Errorf("the %s runtime is not a python runtime", runtime)
+	}
+
+	if architecture == "" {
+		architecture = types.DEFAULT_ARCHITECTURE
+	}
+	machine := "aarch64"
+	if architecture == lambdaTypes.ArchitectureX8664 {
+		machine = "x86_64"
+	}
+
+	return &LambdaPlatform{
+		PythonVersion:  info.Tag,
+		Machine:        machine,
+		Glibc:          info.Glibc,
+		Image:          fmt.Sprintf("%s:%s", info.Image, info.Tag),
+		DockerPlatform: types.DockerPlatform(architecture),
+	}, nil
+}
+
+// CPython ABI of the runtime, IE: cp312
+func (p *LambdaPlatform) ABI() string {
+	return "cp" + strings.ReplaceAll(p.PythonVersion, ".", "")
+}
+
+// Platform tags of the wheels which run on the runtime, IE: manylinux_2_34_aarch64
+// and manylinux2014_aarch64. NOTE: pip accepts every older manylinux tag as well.
+func (p *LambdaPlatform) Tags() []string {
+	tags := []string{}
+	for _, manylinux := range p.manylinux() {
+		tags = append(tags, fmt.Sprintf("%s_%s", manylinux, p.Machine))
+	}
+
+	return tags
+}
+
+// IE: manylinux_2_34 and manylinux2014, newest first
+func (p *LambdaPlatform) manylinux() []string {
+	if p.Glibc == "" {
+		return []string{MANYLINUX_LEGACY_TAG}
+	}
+
+	return []string{"manylinux_" + strings.ReplaceAll(p.Glibc, ".", "_"), MANYLINUX_LEGACY_TAG}
+}
+
+// Arguments of pip install restricting it to wheels of the platform
+func (p *LambdaPlatform) PipArgs() []string {
+	args := []string{}
+	for _, tag := range p.Tags() {
+		args = append(args, "--platform", tag)
+	}
+
+	return append(args, "--implementation", "cp", "--python-version", p.PythonVersion, "--abi", p.ABI(), "--only-binary=:all:")
+}
+
+// Arguments of uv pip install restricting it to wheels of the platform
+func (p *LambdaPlatform) UvArgs() []string {
+	target := fmt.Sprintf("%s-%s", p.Machine, p.manylinux()[0])
+	return []string{"--python-platform", target, "--python-version", p.PythonVersion, "--only-binary", ":all:"}
+}
+
+// Arguments of docker run installing the requirements into the target within the Lambda image
+func (p *LambdaPlatform) ContainerArgs(target string, requirements string) []string {
+	args := []string{"run", "--rm", "--platform", p.DockerPlatform}
+	// NOTE: keep the installed packages owned by the user instead of root
+	if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, gid))
+	}
+
+	return append(args,
+		"--volume", target+":/packages",
+		"--volume", requirements+":/tmp/requirements.txt:ro",
+		"--entrypoint", "python3",
+		p.Image,
+		"-m", "pip", "install", "--quiet", "--no-cache-dir", "--target", "/packages", "--requirement", "/tmp/requirements.txt",
+	)
+}
+
+// Removes __pycache__ and the tests bundled within packages from the installed packages
+func StripPackages(target string) error {
+	return filepath.WalkDir(target, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || !slices.Contains(strippedDirs, d.Name()) {
+			return nil
+		}
+
+		// NOTE: a top level package named test is a dependency, not its tests
+		if d.Name() != "__pycache__" && filepath.Dir(path) == filepath.Clean(target) {
+			return nil
+		}
+
+		err = os.RemoveAll(path)
+		if err != nil {
+			return err
+		}
+		return filepath.SkipDir
+	})
+}
+
+// Top level packages of the installed packages which ship shared objects, sorted
+func NativePackages(target string) ([]string, error) {
+	packages := []string{}
+
+	err := filepath.WalkDir(target, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !(strings.HasSuffix(d.Name(), ".so") || strings.Contains(d.Name(), ".so.")) {
+			return nil
+		}
+
+		relative, err := filepath.Rel(target, path)
+		if err != nil {
+			return err
+		}
+
+		// IE: numpy.libs/libopenblas.so or _cffi_backend.cpython-312-aarch64-linux-gnu.so
+		name := strings.Split(filepath.ToSlash(relative), "/")[0]
+		name, _, _ = strings.Cut(name, ".")
+		if !slices.Contains(packages, name) {
+			packages = append(packages, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(packages)
+	return packages, nil
+}
@@ -0,0 +1,90 @@
+package python
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestNewLambdaPlatform(t *testing.T) {
+	t.Run("should derive the tags of the runtime and architecture", func(t *testing.T) {
+		platform, err := NewLambdaPlatform("python3.11", "x86_64")
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		expected := []string{"manylinux_2_26_x86_64", "manylinux2014_x86_64"}
+		if !slices.Equal(platform.Tags(), expected) {
+			t.Errorf("expected %v, but received %v", expected, platform.Tags())
+		}
+		if platform.ABI() != "cp311" || platform.DockerPlatform != "linux/amd64" {
+			t.Errorf("unexpected platform %+v", platform)
+		}
+	})
+
+	t.Run("should default to arm64", func(t *testing.T) {
+		platform, _ := NewLambdaPlatform("python3.13", "")
+		if args := platform.UvArgs(); !slices.Contains(args, "aarch64-manylinux_2_34") {
+			t.Errorf("unexpected uv arguments %v", args)
+		}
+	})
+
+	t.Run("should fail for other runtimes", func(t *testing.T) {
+		_, err := NewLambdaPlatform("nodejs22.x", "arm64")
+		if err == nil {
+			t.Errorf("expected an error for a node runtime")
+		}
+	})
+}
+
+func TestStripPackages(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"numpy/__init__.py":                  "",
+		"numpy/__pycache__/__init__.pyc":     "",
+		"numpy/tests/test_numpy.py":          "",
+		"numpy/core/tests/test_core.py":      "",
+		"test/__init__.py":                   "",
+		"requests-2.32.3.dist-info/METADATA": "",
+	})
+
+	err := StripPackages(dir)
+	if err != nil {
+		t.Errorf("expected no errors, but received \"%s\"", err.Error())
+		return
+	}
+
+	for _, removed := range []string{"numpy/__pycache__", "numpy/tests", "numpy/core/tests"} {
+		if _, err := os.Stat(filepath.Join(dir, removed)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", removed)
+		}
+	}
+	for _, kept := range []string{"numpy/__init__.py", "test/__init__.py", "requests-2.32.3.dist-info/METADATA"} {
+		if _, err := os.Stat(filepath.Join(dir, kept)); err != nil {
+			t.Errorf("expected %s to be kept", kept)
+		}
+	}
+}
+
+func TestNativePackages(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"requests/__init__.py": "",
+		"pydantic_core/_pydantic_core.cpython-312-aarch64-linux-gnu.so": "",
+		"numpy.libs/libopenblas64_p-r0-2f7c42d4.3.18.so":                "",
+		"_cffi_backend.cpython-312-aarch64-linux-gnu.so":                "",
+	})
+
+	packages, err := NativePackages(dir)
+	if err != nil {
+		t.Errorf("expected no errors, but received \"%s\"", err.Error())
+		return
+	}
+
+	expected := []string{"_cffi_backend", "numpy", "pydantic_core"}
+	if !slices.Equal(packages, expected) {
+		t.Errorf("expected %v, but received %v", expected, packages)
+	}
+}
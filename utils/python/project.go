@@ -0,0 +1,344 @@
+package python
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Directory within the project the dependencies are installed into for Lambda
+const PACKAGES_DIR string = ".jeeves/python"
+
+// Names checked for a venv before any other directory of the project
+var venvNames = []string{".venv", "venv", "env"}
+
+// How the dependencies of a python project are declared
+type DependencyManager string
+
+const (
+	// uv.lock
+	MANAGER_UV DependencyManager = "uv"
+	// poetry.lock or [tool.poetry] of pyproject.toml
+	MANAGER_POETRY DependencyManager = "poetry"
+	// requirements.txt
+	MANAGER_PIP DependencyManager = "pip"
+	// dependencies of [project] of pyproject.toml
+	MANAGER_PYPROJECT DependencyManager = "pyproject"
+	// Nothing declared, the packages installed into the project's venv
+	MANAGER_VENV DependencyManager = "venv"
+	// Nothing declared and no venv
+	MANAGER_NONE DependencyManager = "none"
+)
+
+// Runs the command within the directory and returns its output
+var RunCommand func(dir string, name string, args ...string) ([]byte, error)
+
+func init() {
+	RunCommand = runCommand
+}
+
+type PythonProject struct {
+	Dir     string
+	Manager DependencyManager
+	// Venv within Dir, relative to it, empty when the project has none
+	Venv string
+	// Python version of the venv read from its pyvenv.cfg, IE: python3.12
+	VenvVersion string
+}
+
+type pyproject struct {
+	Project struct {
+		Dependencies []string `toml:"dependencies"`
+	} `toml:"project"`
+	Tool struct {
+		Poetry map[string]any `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+// Detects the venv and the dependency manager of the python project within the directory
+func DetectProject(dir string) (*PythonProject, error) {
+	project := &PythonProject{Dir: dir}
+
+	venv, err := FindVenv(dir)
+	if err != nil {
+		return nil, err
+	}
+	if venv != "" {
+		project.Venv = venv
+		project.VenvVersion, err = ReadVenvVersion(filepath.Join(dir, venv))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	project.Manager, err = detectManager(dir, venv != "")
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// Finds the venv of the project: the active venv when it is within the directory,
+// then .venv, venv or env, then any other directory containing a pyvenv.cfg.
+// Returns the venv relative to the directory, empty when there is none.
+func FindVenv(dir string) (string, error) {
+	if active := os.Getenv(VIRTUAL_ENV); active != "" {
+		relative, err := filepath.Rel(dir, active)
+		if err == nil && !strings.HasPrefix(relative, "..") && isVenv(active) {
+			return relative, nil
+		}
+	}
+
+	for _, name := range venvNames {
+		if isVenv(filepath.Join(dir, name)) {
+			return name, nil
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() && isVenv(filepath.Join(dir, entry.Name())) {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) == 0 {
+		return "", nil
+	}
+
+	sort.Strings(names)
+	return names[0], nil
+}
+
+func isVenv(path string) bool {
+	_, err := os.Stat(filepath.Join(path, "pyvenv.cfg"))
+	return err == nil
+}
+
+// Reads the python version of the venv from its pyvenv.cfg, IE: python3.12
+func ReadVenvVersion(venv string) (string, error) {
+	file, err := os.Open(filepath.Join(venv, "pyvenv.cfg"))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found {
+			continue
+		}
+
+		// NOTE: venv writes "version", virtualenv and uv write "version_info", IE: 3.12.1.final.0
+		switch strings.TrimSpace(key) {
+		case "version", "version_info":
+			return formatPythonVersion("Python " + strings.TrimSpace(value)), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", fmt.Errorf("no python version found in %s", filepath.Join(venv, "pyvenv.cfg"))
+}
+
+func detectManager(dir string, hasVenv bool) (DependencyManager, error) {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	switch {
+	case exists("uv.lock"):
+		return MANAGER_UV, nil
+	case exists("poetry.lock"):
+		return MANAGER_POETRY, nil
+	case exists("requirements.txt"):
+		return MANAGER_PIP, nil
+	}
+
+	project, err := readPyproject(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		if hasVenv {
+			return MANAGER_VENV, nil
+		}
+		return MANAGER_NONE, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case project.Tool.Poetry != nil:
+		return MANAGER_POETRY, nil
+	case len(project.Project.Dependencies) == 0 && hasVenv:
+		// NOTE: dependencies installed with pip install without declaring them
+		return MANAGER_VENV, nil
+	}
+
+	return MANAGER_PYPROJECT, nil
+}
+
+func readPyproject(dir string) (*pyproject, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "pyproject.toml"))
+	if err != nil {
+		return nil, err
+	}
+
+	project := new(pyproject)
+	err = toml.Unmarshal(data, project)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pyproject.toml: %w", err)
+	}
+
+	return project, nil
+}
+
+// Requirements of the project in the requirements.txt format, exported from the
+// lock file of uv or poetry. Development dependencies are left out.
+func (p *PythonProject) Requirements() (string, error) {
+	switch p.Manager {
+	case MANAGER_UV:
+		output, err := RunCommand(p.Dir, "uv", "export", "--format", "requirements-txt", "--frozen", "--no-dev", "--no-hashes", "--no-emit-project")
+		return string(output), err
+	case MANAGER_POETRY:
+		// NOTE: poetry 2 requires the poetry-plugin-export plugin
+		output, err := RunCommand(p.Dir, "poetry", "export", "--format", "requirements.txt", "--only", "main", "--without-hashes")
+		return string(output), err
+	case MANAGER_PIP:
+		data, err := os.ReadFile(filepath.Join(p.Dir, "requirements.txt"))
+		return string(data), err
+	case MANAGER_PYPROJECT:
+		project, err := readPyproject(p.Dir)
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(project.Project.Dependencies, "\n"), nil
+	case MANAGER_VENV:
+		output, err := RunCommand(p.Dir, p.Python(), "-m", "pip", "freeze", "--exclude-editable")
+		return string(output), err
+	}
+
+	return "", nil
+}
+
+// Interpreter of the project's venv, python3 of the PATH without a venv
+func (p *PythonProject) Python() string {
+	if p.Venv == "" {
+		return "python3"
+	}
+
+	return filepath.Join(p.Dir, p.Venv, "bin", "python")
+}
+
+// Installs the requirements into the directory for the Lambda platform, replacing what
+// was installed before. Only wheels of the platform are installed on the host, when a
+// requirement has none, IE: an sdist, everything is installed within the Lambda image.
+func (p *PythonProject) Vendor(target string, platform *LambdaPlatform) (*VendorReport, error) {
+	requirements, err := p.Requirements()
+	if err != nil {
+		return nil, fmt.Errorf("could not read the %s dependencies: %w", p.Manager, err)
+	}
+
+	target, err = filepath.Abs(target)
+	if err != nil {
+		return nil, err
+	}
+	err = resetDir(target)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VendorReport{Native: []string{}}
+	if strings.TrimSpace(requirements) == "" {
+		return report, nil
+	}
+
+	file, err := os.CreateTemp("", "requirements-*.txt")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(requirements)
+	file.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: venvs created by uv come without pip
+	install := append([]string{p.Python(), "-m", "pip", "install"}, platform.PipArgs()...)
+	if p.Manager == MANAGER_UV {
+		install = append([]string{"uv", "pip", "install"}, platform.UvArgs()...)
+	}
+	install = append(install, "--quiet", "--target", target, "--requirement", file.Name())
+
+	_, err = RunCommand(p.Dir, install[0], install[1:]...)
+	if err != nil {
+		wheelErr := err
+		err = resetDir(target)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = RunCommand(p.Dir, "docker", platform.ContainerArgs(target, file.Name())...)
+		if err != nil {
+			return nil, fmt.Errorf("%w, installing within %s failed as well: %w", wheelErr, platform.Image, err)
+		}
+		report.Container = true
+	}
+
+	err = StripPackages(target)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Native, err = NativePackages(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func resetDir(dir string) error {
+	err := os.RemoveAll(dir)
+	if err != nil {
+		return err
+	}
+
+	return os.MkdirAll(dir, 0755)
+}
+
+// Dependencies are vendored into PACKAGES_DIR, see Vendor
+func (p *PythonProject) DependencyPath() (string, error) {
+	return PACKAGES_DIR, nil
+}
+
+func runCommand(dir string, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %s", name, args[0], strings.TrimSpace(stderr.String()))
+	}
+
+	return output, nil
+}
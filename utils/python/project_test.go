@@ -0,0 +1,212 @@
+package python
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		err := os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetectProject(t *testing.T) {
+	t.Setenv(VIRTUAL_ENV, "")
+
+	cases := []struct {
+		name    string
+		files   map[string]string
+		manager DependencyManager
+		venv    string
+		version string
+	}{
+		{"uv", map[string]string{"uv.lock": "", "requirements.txt": "", ".venv/pyvenv.cfg": "version_info = 3.12.4.final.0"}, MANAGER_UV, ".venv", "python3.12"},
+		{"poetry", map[string]string{"poetry.lock": "", "requirements.txt": ""}, MANAGER_POETRY, "", ""},
+		{"pip", map[string]string{"requirements.txt": "requests", "venv/pyvenv.cfg": "home = /usr/bin\nversion = 3.11.9"}, MANAGER_PIP, "venv", "python3.11"},
+		{"poetry pyproject", map[string]string{"pyproject.toml": "[tool.poetry]\nname = \"handler\""}, MANAGER_POETRY, "", ""},
+		{"pyproject", map[string]string{"pyproject.toml": "[project]\ndependencies = [\"requests\"]"}, MANAGER_PYPROJECT, "", ""},
+		{"named venv", map[string]string{"pyproject.toml": "[project]\nname = \"handler\"", "lambda-env/pyvenv.cfg": "version = 3.13.0"}, MANAGER_VENV, "lambda-env", "python3.13"},
+		{"nothing", map[string]string{"src/main.py": ""}, MANAGER_NONE, "", ""},
+	}
+
+	for _, c := range cases {
+		t.Run("should detect "+c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, c.files)
+
+			project, err := DetectProject(dir)
+			if err != nil {
+				t.Errorf("expected no errors, but received \"%s\"", err.Error())
+				return
+			}
+			if project.Manager != c.manager || project.Venv != c.venv || project.VenvVersion != c.version {
+				t.Errorf("expected %s %s %s, but received %s %s %s", c.manager, c.venv, c.version, project.Manager, project.Venv, project.VenvVersion)
+			}
+		})
+	}
+
+	t.Run("should prefer the active venv within the project", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{".venv/pyvenv.cfg": "version = 3.12.0", "py313/pyvenv.cfg": "version = 3.13.0"})
+		t.Setenv(VIRTUAL_ENV, filepath.Join(dir, "py313"))
+
+		venv, err := FindVenv(dir)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if venv != "py313" {
+			t.Errorf("expected py313, but received %s", venv)
+		}
+	})
+
+	t.Run("should ignore an active venv outside of the project", func(t *testing.T) {
+		dir := t.TempDir()
+		other := t.TempDir()
+		writeFiles(t, other, map[string]string{"pyvenv.cfg": "version = 3.13.0"})
+		t.Setenv(VIRTUAL_ENV, other)
+
+		venv, _ := FindVenv(dir)
+		if venv != "" {
+			t.Errorf("expected no venv, but received %s", venv)
+		}
+	})
+}
+
+func TestRequirements(t *testing.T) {
+	commands := [][]string{}
+	RunCommand = func(dir string, name string, args ...string) ([]byte, error) {
+		commands = append(commands, append([]string{name}, args...))
+		return []byte("requests==2.32.3\n"), nil
+	}
+	defer func() { RunCommand = runCommand }()
+
+	t.Run("should read the dependencies of pyproject.toml", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"pyproject.toml": "[project]\ndependencies = [\"requests>=2\", \"boto3\"]"})
+
+		project := &PythonProject{Dir: dir, Manager: MANAGER_PYPROJECT}
+		requirements, err := project.Requirements()
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+		if requirements != "requests>=2\nboto3" {
+			t.Errorf("unexpected requirements\n%s", requirements)
+		}
+	})
+
+	t.Run("should export the uv lock file without dev dependencies", func(t *testing.T) {
+		project := &PythonProject{Dir: t.TempDir(), Manager: MANAGER_UV}
+		_, err := project.Requirements()
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		last := commands[len(commands)-1]
+		if last[0] != "uv" || !slices.Contains(last, "--no-dev") {
+			t.Errorf("unexpected command %v", last)
+		}
+	})
+
+	t.Run("should freeze the packages of the venv", func(t *testing.T) {
+		dir := t.TempDir()
+		project := &PythonProject{Dir: dir, Manager: MANAGER_VENV, Venv: ".venv"}
+		_, err := project.Requirements()
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		last := commands[len(commands)-1]
+		if last[0] != filepath.Join(dir, ".venv/bin/python") || !slices.Contains(last, "freeze") {
+			t.Errorf("unexpected command %v", last)
+		}
+	})
+}
+
+func TestVendor(t *testing.T) {
+	commands := [][]string{}
+	RunCommand = func(dir string, name string, args ...string) ([]byte, error) {
+		commands = append(commands, append([]string{name}, args...))
+		return nil, nil
+	}
+	defer func() { RunCommand = runCommand }()
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, PACKAGES_DIR)
+	platform, _ := NewLambdaPlatform("python3.12", "arm64")
+
+	t.Run("should install wheels of the platform into the target", func(t *testing.T) {
+		writeFiles(t, dir, map[string]string{"requirements.txt": "requests", PACKAGES_DIR + "/stale/__init__.py": ""})
+
+		project := &PythonProject{Dir: dir, Manager: MANAGER_PIP}
+		report, err := project.Vendor(target, platform)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		install := commands[len(commands)-1]
+		for _, expected := range []string{"python3", "--target", target, "manylinux2014_aarch64", "cp312", "--only-binary=:all:"} {
+			if !slices.Contains(install, expected) {
+				t.Errorf("expected %s within %v", expected, install)
+			}
+		}
+		if report.Container {
+			t.Errorf("expected no container install")
+		}
+		if _, err := os.Stat(filepath.Join(target, "stale")); !os.IsNotExist(err) {
+			t.Errorf("expected previously vendored packages to be removed")
+		}
+	})
+
+	t.Run("should install within the Lambda image without a wheel", func(t *testing.T) {
+		RunCommand = func(dir string, name string, args ...string) ([]byte, error) {
+			commands = append(commands, append([]string{name}, args...))
+			if name != "docker" {
+				return nil, errors.New("no matching distribution found for sdist-only")
+			}
+			return nil, nil
+		}
+
+		project := &PythonProject{Dir: dir, Manager: MANAGER_PIP}
+		report, err := project.Vendor(target, platform)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		install := commands[len(commands)-1]
+		if !report.Container || !slices.Contains(install, "amazon/aws-lambda-python:3.12") || !slices.Contains(install, "linux/arm64") {
+			t.Errorf("unexpected command %v", install)
+		}
+	})
+
+	t.Run("should only create the target without dependencies", func(t *testing.T) {
+		count := len(commands)
+		project := &PythonProject{Dir: dir, Manager: MANAGER_NONE}
+		_, err := project.Vendor(target, platform)
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if len(commands) != count {
+			t.Errorf("expected nothing to be installed, but ran %v", commands[len(commands)-1])
+		}
+		if _, err := os.Stat(target); err != nil {
+			t.Errorf("expected the target to exist")
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const GITHUB_API_URL string = "https://api.github.com"
+
+// Provisions repositories from template repositories with the GitHub REST API
+type GitHubProvider struct {
+	// API url, defaults to GITHUB_API_URL
+	Url   string
+	Token string
+	// User or organization owning the new repositories
+	Owner         string
+	Visibility    string
+	TemplateOwner string
+	Client        *http.Client
+}
+
+type gitHubGenerateRequest struct {
+	Owner       string `json:"owner"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Private     bool   `json:"private"`
+}
+
+type gitHubTopicsRequest struct {
+	Names []string `json:"names"`
+}
+
+type gitHubRepository struct {
+	Name    string `json:"name"`
+	HtmlUrl string `json:"html_url"`
+}
+
+func (p *GitHubProvider) Create(input *CreateInput) (*Repository, error) {
+	req, err := p.newRequest(http.MethodPost, fmt.Sprintf("/repos/%s/%s/generate", p.TemplateOwner, input.TemplateRepo))
+	if err != nil {
+		return nil, err
+	}
+
+	repository := new(gitHubRepository)
+	err = doJson(p.Client, req, &gitHubGenerateRequest{
+		Owner:       p.Owner,
+		Name:        input.Name,
+		Description: input.Description,
+		// NOTE: templates can only produce public or private repositories
+		Private: p.Visibility != "public",
+	}, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: generating from a template does not accept topics, they are replaced afterwards
+	if len(input.Tags) > 0 {
+		req, err = p.newRequest(http.MethodPut, fmt.Sprintf("/repos/%s/%s/topics", p.Owner, repository.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		err = doJson(p.Client, req, &gitHubTopicsRequest{Names: Topics(input.Tags)}, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Repository{Name: repository.Name, Url: repository.HtmlUrl}, nil
+}
+
+func (p *GitHubProvider) Delete(name string) error {
+	req, err := p.newRequest(http.MethodDelete, fmt.Sprintf("/repos/%s/%s", p.Owner, name))
+	if err != nil {
+		return err
+	}
+
+	return doJson(p.Client, req, nil, nil)
+}
+
+func (p *GitHubProvider) newRequest(method string, path string) (*http.Request, error) {
+	url := p.Url
+	if url == "" {
+		url = GITHUB_API_URL
+	}
+
+	req, err := http.NewRequestWithContext(context.TODO(), method, strings.TrimSuffix(url, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.Token))
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	return req, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitHubProvider(t *testing.T) {
+	var received gitHubGenerateRequest
+	var topics gitHubTopicsRequest
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/templates/nodejs-lambda/generate":
+			json.NewDecoder(r.Body).Decode(&received)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"name": "my-function.lambda", "html_url": "https://github.com/my-org/my-function.lambda"}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/repos/my-org/my-function.lambda/topics":
+			json.NewDecoder(r.Body).Decode(&topics)
+			w.Write([]byte(`{"names": []}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/repos/my-org/my-function.lambda":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := &GitHubProvider{
+		Url:           server.URL,
+		Token:         "secret-token",
+		Owner:         "my-org",
+		Visibility:    "private",
+		TemplateOwner: "templates",
+	}
+
+	t.Run("should generate the repository from the template", func(t *testing.T) {
+		repo, err := provider.Create(&CreateInput{Name: "my-function.lambda", TemplateRepo: "nodejs-lambda", Tags: map[string]string{"jeeves:managed": "true"}})
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if repo.Url != "https://github.com/my-org/my-function.lambda" {
+			t.Errorf("unexpected repository url \"%s\"", repo.Url)
+		}
+		if received.Owner != "my-org" || received.Name != "my-function.lambda" || !received.Private {
+			t.Errorf("unexpected generate request %+v", received)
+		}
+		if len(topics.Names) != 1 || topics.Names[0] != "jeeves-managed-true" {
+			t.Errorf("unexpected topics %v", topics.Names)
+		}
+	})
+
+	t.Run("should delete the repository", func(t *testing.T) {
+		err := provider.Delete("my-function.lambda")
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+		}
+	})
+
+	t.Run("should report API errors", func(t *testing.T) {
+		err := provider.Delete("missing.lambda")
+		if err == nil {
+			t.Errorf("expected an error for a missing repository, requests: %v", requests)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const GITLAB_API_URL string = "https://gitlab.com/api/v4"
+
+// Url the GitLab provider imports the runtime templates from
+const TEMPLATE_GIT_URL string = "https://github.com/%s/%s.git"
+
+// Provisions projects with the GitLab REST API by importing the template repository
+type GitLabProvider struct {
+	// API url, defaults to GITLAB_API_URL
+	Url   string
+	Token string
+	// Full path of the group or user owning the new projects, IE: my-group/lambdas
+	Namespace     string
+	Visibility    string
+	TemplateOwner string
+	Client        *http.Client
+}
+
+type gitLabNamespace struct {
+	Id int `json:"id"`
+}
+
+type gitLabCreateProjectRequest struct {
+	Name        string   `json:"name"`
+	Path        string   `json:"path"`
+	NamespaceId int      `json:"namespace_id"`
+	Description string   `json:"description,omitempty"`
+	Visibility  string   `json:"visibility"`
+	ImportUrl   string   `json:"import_url"`
+	Topics      []string `json:"topics,omitempty"`
+}
+
+type gitLabProject struct {
+	Name   string `json:"name"`
+	WebUrl string `json:"web_url"`
+}
+
+func (p *GitLabProvider) Create(input *CreateInput) (*Repository, error) {
+	req, err := p.newRequest(http.MethodGet, fmt.Sprintf("/namespaces/%s", url.PathEscape(p.Namespace)))
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := new(gitLabNamespace)
+	err = doJson(p.Client, req, nil, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err = p.newRequest(http.MethodPost, "/projects")
+	if err != nil {
+		return nil, err
+	}
+
+	project := new(gitLabProject)
+	err = doJson(p.Client, req, &gitLabCreateProjectRequest{
+		Name:        input.Name,
+		Path:        input.Name,
+		NamespaceId: namespace.Id,
+		Description: input.Description,
+		Visibility:  p.Visibility,
+		ImportUrl:   fmt.Sprintf(TEMPLATE_GIT_URL, p.TemplateOwner, input.TemplateRepo),
+		Topics:      Topics(input.Tags),
+	}, project)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Repository{Name: project.Name, Url: project.WebUrl}, nil
+}
+
+func (p *GitLabProvider) Delete(name string) error {
+	req, err := p.newRequest(http.MethodDelete, fmt.Sprintf("/projects/%s", url.PathEscape(fmt.Sprintf("%s/%s", p.Namespace, name))))
+	if err != nil {
+		return err
+	}
+
+	return doJson(p.Client, req, nil, nil)
+}
+
+func (p *GitLabProvider) newRequest(method string, path string) (*http.Request, error) {
+	apiUrl := p.Url
+	if apiUrl == "" {
+		apiUrl = GITLAB_API_URL
+	}
+
+	// NOTE: keep escaped slashes in project paths, IE: my-group%2Fmy-project
+	req, err := http.NewRequestWithContext(context.TODO(), method, strings.TrimSuffix(apiUrl, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("PRIVATE-TOKEN", p.Token)
+
+	return req, nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitLabProvider(t *testing.T) {
+	var received gitLabCreateProjectRequest
+	deleted := ""
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.EscapedPath() == "/namespaces/my-group%2Flambdas":
+			w.Write([]byte(`{"id": 42}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/projects":
+			json.NewDecoder(r.Body).Decode(&received)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"name": "my-function.lambda", "web_url": "https://gitlab.com/my-group/lambdas/my-function.lambda"}`))
+		case r.Method == http.MethodDelete:
+			deleted = r.URL.EscapedPath()
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := &GitLabProvider{
+		Url:           server.URL,
+		Token:         "secret-token",
+		Namespace:     "my-group/lambdas",
+		Visibility:    "internal",
+		TemplateOwner: "templates",
+	}
+
+	t.Run("should import the template into a new project", func(t *testing.T) {
+		repo, err := provider.Create(&CreateInput{Name: "my-function.lambda", TemplateRepo: "go-lambda", Tags: map[string]string{"jeeves:project": "my-function"}})
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if repo.Url != "https://gitlab.com/my-group/lambdas/my-function.lambda" {
+			t.Errorf("unexpected project url \"%s\"", repo.Url)
+		}
+		if received.NamespaceId != 42 || received.Visibility != "internal" || received.ImportUrl != "https://github.com/templates/go-lambda.git" {
+			t.Errorf("unexpected create project request %+v", received)
+		}
+		if len(received.Topics) != 1 || received.Topics[0] != "jeeves-project-my-function" {
+			t.Errorf("unexpected topics %v", received.Topics)
+		}
+	})
+
+	t.Run("should delete the project by its escaped path", func(t *testing.T) {
+		err := provider.Delete("my-function.lambda")
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if deleted != "/projects/my-group%2Flambdas%2Fmy-function.lambda" {
+			t.Errorf("unexpected delete path \"%s\"", deleted)
+		}
+	})
+}
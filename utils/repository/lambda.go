@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/obscurelyme/jeeves/types"
+)
+
+const CREATE_LAMBDA_REPOSITORY string = "create-lambda-repository"
+const DELETE_LAMBDA_REPOSITORY string = "delete-lambda-repository"
+
+// Provisions repositories by invoking the create-lambda-repository and
+// delete-lambda-repository Lambdas deployed within the AWS account
+type LambdaProvider struct {
+	Cfg           aws.Config
+	Owner         string
+	Visibility    string
+	TemplateOwner string
+}
+
+func (p *LambdaProvider) Create(input *CreateInput) (*Repository, error) {
+	creds, err := p.Cfg.Credentials.Retrieve(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	functionName := fmt.Sprintf("arn:aws:lambda:%s:%s:function:%s", p.Cfg.Region, creds.AccountID, CREATE_LAMBDA_REPOSITORY)
+	err = p.invoke(functionName, &types.Payload{
+		TemplateRepo:          input.TemplateRepo,
+		TemplateOwner:         p.TemplateOwner,
+		Owner:                 p.Owner,
+		RepositoryName:        input.Name,
+		RepositoryDescription: input.Description,
+		Visibility:            p.Visibility,
+		Tags:                  input.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Repository{Name: input.Name, Url: fmt.Sprintf("https://github.com/%s/%s", p.Owner, input.Name)}, nil
+}
+
+func (p *LambdaProvider) Delete(name string) error {
+	return p.invoke(DELETE_LAMBDA_REPOSITORY, &types.DeleteRepositoryPayload{
+		RepositoryOwner: p.Owner,
+		RepositoryName:  name,
+	})
+}
+
+func (p *LambdaProvider) invoke(functionName string, payload any) error {
+	client := lambda.NewFromConfig(p.Cfg)
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	output, err := client.Invoke(context.TODO(), &lambda.InvokeInput{
+		FunctionName: &functionName,
+		Payload:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	if output.FunctionError != nil {
+		return fmt.Errorf("%s failed: %s", functionName, string(output.Payload))
+	}
+
+	if output.StatusCode != http.StatusOK {
+		return fmt.Errorf("lambda failed with status code: %d", output.StatusCode)
+	}
+
+	return nil
+}
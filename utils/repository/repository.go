@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/obscurelyme/jeeves/utils"
+)
+
+const (
+	PROVIDER_GITHUB string = "github"
+	PROVIDER_GITLAB string = "gitlab"
+	PROVIDER_LAMBDA string = "lambda"
+	PROVIDER_NONE   string = "none"
+)
+
+// Owner of the runtime template repositories and of the repositories
+// provisioned through the Lambda proxy when nothing else is configured
+const DEFAULT_OWNER string = "obscurelyme"
+
+const DEFAULT_VISIBILITY string = "private"
+
+type CreateInput struct {
+	// Name of the new repository
+	Name        string
+	Description string
+	// Template repository the new repository starts from, IE: nodejs-lambda
+	TemplateRepo string
+	// Standard tags of resources created by jeeves, see tags.New
+	Tags map[string]string
+}
+
+type Repository struct {
+	Name string
+	// Web url of the repository, empty when no repository was created
+	Url string
+}
+
+// Provisions and removes the source repositories of FaaS resources
+type Provider interface {
+	Create(input *CreateInput) (*Repository, error)
+	Delete(name string) error
+}
+
+// Creates the provider named by the settings, falling back to the Lambda proxy
+// when no provider is configured. An explicit name takes precedence over the settings.
+func New(name string, settings utils.JeevesRepository, cfg aws.Config) (Provider, error) {
+	if name == "" {
+		name = settings.Provider
+	}
+
+	templateOwner := settings.TemplateOwner
+	if templateOwner == "" {
+		templateOwner = DEFAULT_OWNER
+	}
+
+	visibility := settings.Visibility
+	if visibility == "" {
+		visibility = DEFAULT_VISIBILITY
+	}
+
+	switch name {
+	case PROVIDER_GITHUB:
+		token := tokenOrEnv(settings.Token, "GITHUB_TOKEN")
+		if token == "" {
+			return nil, fmt.Errorf("the github provider requires a token, set Repository.Token in .jeeves.yaml or GITHUB_TOKEN")
+		}
+		if settings.Owner == "" {
+			return nil, fmt.Errorf("the github provider requires Repository.Owner in .jeeves.yaml")
+		}
+		return &GitHubProvider{
+			Url:           settings.Url,
+			Token:         token,
+			Owner:         settings.Owner,
+			Visibility:    visibility,
+			TemplateOwner: templateOwner,
+		}, nil
+	case PROVIDER_GITLAB:
+		token := tokenOrEnv(settings.Token, "GITLAB_TOKEN")
+		if token == "" {
+			return nil, fmt.Errorf("the gitlab provider requires a token, set Repository.Token in .jeeves.yaml or GITLAB_TOKEN")
+		}
+		if settings.Owner == "" {
+			return nil, fmt.Errorf("the gitlab provider requires Repository.Owner in .jeeves.yaml")
+		}
+		return &GitLabProvider{
+			Url:           settings.Url,
+			Token:         token,
+			Namespace:     settings.Owner,
+			Visibility:    visibility,
+			TemplateOwner: templateOwner,
+		}, nil
+	case PROVIDER_LAMBDA, "":
+		owner := settings.Owner
+		if owner == "" {
+			owner = DEFAULT_OWNER
+		}
+		// NOTE: the proxy has always created public repositories
+		if settings.Visibility == "" {
+			visibility = "public"
+		}
+		return &LambdaProvider{
+			Cfg:           cfg,
+			Owner:         owner,
+			Visibility:    visibility,
+			TemplateOwner: templateOwner,
+		}, nil
+	case PROVIDER_NONE:
+		return &NoneProvider{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown repository provider \"%s\", expected one of github, gitlab, lambda or none", name)
+}
+
+// Characters topics of GitHub and GitLab do not allow
+var invalidTopicCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Longest topic GitHub accepts
+const MAX_TOPIC_LENGTH int = 50
+
+// Turns the tags into topics, the only labels repositories have, with the key and value
+// of each tag joined, IE: jeeves:managed=true -> jeeves-managed-true
+func Topics(tags map[string]string) []string {
+	topics := []string{}
+	for key, value := range tags {
+		topic := strings.Trim(invalidTopicCharacters.ReplaceAllString(strings.ToLower(key+"-"+value), "-"), "-")
+		if len(topic) > MAX_TOPIC_LENGTH {
+			topic = strings.TrimRight(topic[:MAX_TOPIC_LENGTH], "-")
+		}
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	return topics
+}
+
+func tokenOrEnv(token string, env string) string {
+	if token != "" {
+		return token
+	}
+
+	return os.Getenv(env)
+}
+
+// Sends a JSON request, decoding the JSON response into out when given
+func doJson(client *http.Client, req *http.Request, body any, out any) error {
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.ContentLength = int64(len(data))
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("%s %s failed with status code %d: %s", req.Method, req.URL.Path, res.StatusCode, bytes.TrimSpace(data))
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, out)
+}
+
+// Provider which does not provision repositories, IE: for code kept in an existing monorepo
+type NoneProvider struct{}
+
+func (p *NoneProvider) Create(input *CreateInput) (*Repository, error) {
+	return &Repository{Name: input.Name}, nil
+}
+
+func (p *NoneProvider) Delete(name string) error {
+	return nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/obscurelyme/jeeves/utils"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("should default to the lambda proxy with public repositories", func(t *testing.T) {
+		provider, err := New("", utils.JeevesRepository{}, aws.Config{})
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		lambdaProvider, ok := provider.(*LambdaProvider)
+		if !ok {
+			t.Errorf("expected a LambdaProvider, but received %T", provider)
+			return
+		}
+		if lambdaProvider.Owner != DEFAULT_OWNER || lambdaProvider.Visibility != "public" {
+			t.Errorf("unexpected lambda provider %+v", lambdaProvider)
+		}
+	})
+
+	t.Run("should prefer the given provider over the settings", func(t *testing.T) {
+		provider, err := New(PROVIDER_NONE, utils.JeevesRepository{Provider: PROVIDER_GITHUB}, aws.Config{})
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		if _, ok := provider.(*NoneProvider); !ok {
+			t.Errorf("expected a NoneProvider, but received %T", provider)
+		}
+	})
+
+	t.Run("should read the github token from the environment", func(t *testing.T) {
+		t.Setenv("GITHUB_TOKEN", "env-token")
+
+		provider, err := New(PROVIDER_GITHUB, utils.JeevesRepository{Owner: "my-org"}, aws.Config{})
+		if err != nil {
+			t.Errorf("expected no errors, but received \"%s\"", err.Error())
+			return
+		}
+
+		github := provider.(*GitHubProvider)
+		if github.Token != "env-token" || github.Visibility != DEFAULT_VISIBILITY || github.TemplateOwner != DEFAULT_OWNER {
+			t.Errorf("unexpected github provider %+v", github)
+		}
+	})
+
+	t.Run("should reject unknown providers and missing settings", func(t *testing.T) {
+		t.Setenv("GITLAB_TOKEN", "")
+
+		if _, err := New("bitbucket", utils.JeevesRepository{}, aws.Config{}); err == nil {
+			t.Errorf("expected an error for an unknown provider")
+		}
+		if _, err := New(PROVIDER_GITLAB, utils.JeevesRepository{Owner: "my-group"}, aws.Config{}); err == nil {
+			t.Errorf("expected an error for a missing gitlab token")
+		}
+	})
+}
+
+func TestTopics(t *testing.T) {
+	t.Run("should turn the tags into valid topics", func(t *testing.T) {
+		topics := Topics(map[string]string{
+			"jeeves:managed": "true",
+			"jeeves:owner":   "arn:aws:iam::123456789012:user/Dev",
+		})
+
+		expected := []string{"jeeves-managed-true", "jeeves-owner-arn-aws-iam-123456789012-user-dev"}
+		if !slices.Equal(topics, expected) {
+			t.Errorf("expected topics %v, but received %v", expected, topics)
+		}
+	})
+
+	t.Run("should shorten topics to the longest GitHub accepts", func(t *testing.T) {
+		topics := Topics(map[string]string{"jeeves:owner": strings.Repeat("a", 100)})
+		if len(topics[0]) != MAX_TOPIC_LENGTH {
+			t.Errorf("expected a topic of %d characters, but received \"%s\"", MAX_TOPIC_LENGTH, topics[0])
+		}
+	})
+}
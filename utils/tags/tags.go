@@ -0,0 +1,78 @@
+package tags
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ecrTypes "github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	eventbridgeTypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// Marks resources created by jeeves
+const TAG_MANAGED string = "jeeves:managed"
+
+// Caller identity which created the resource
+const TAG_OWNER string = "jeeves:owner"
+
+// FaaS resource the resource belongs to
+const TAG_PROJECT string = "jeeves:project"
+
+// When the resource was created, in RFC 3339
+const TAG_CREATED_AT string = "created-at"
+
+// Builds the standard tags of a resource created by jeeves
+func Standard(owner string, project string, createdAt time.Time) map[string]string {
+	return map[string]string{
+		TAG_MANAGED:    "true",
+		TAG_OWNER:      owner,
+		TAG_PROJECT:    project,
+		TAG_CREATED_AT: createdAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// Builds the standard tags with the caller identity of the config as the owner
+func New(cfg aws.Config, project string) (map[string]string, error) {
+	client := sts.NewFromConfig(cfg)
+
+	identity, err := client.GetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	return Standard(aws.ToString(identity.Arn), project, time.Now()), nil
+}
+
+// Checks whether the tags mark a resource created by jeeves
+func IsManaged(tags map[string]string) bool {
+	return tags[TAG_MANAGED] == "true"
+}
+
+func ToIAM(tags map[string]string) []iamTypes.Tag {
+	iamTags := []iamTypes.Tag{}
+	for key, value := range tags {
+		iamTags = append(iamTags, iamTypes.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	return iamTags
+}
+
+func ToEventBridge(tags map[string]string) []eventbridgeTypes.Tag {
+	eventbridgeTags := []eventbridgeTypes.Tag{}
+	for key, value := range tags {
+		eventbridgeTags = append(eventbridgeTags, eventbridgeTypes.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	return eventbridgeTags
+}
+
+func ToECR(tags map[string]string) []ecrTypes.Tag {
+	ecrTags := []ecrTypes.Tag{}
+	for key, value := range tags {
+		ecrTags = append(ecrTags, ecrTypes.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	return ecrTags
+}
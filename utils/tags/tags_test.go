@@ -0,0 +1,36 @@
+package tags
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStandard(t *testing.T) {
+	t.Run("should build the standard tags of a managed resource", func(t *testing.T) {
+		createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("EST", -5*60*60))
+		tags := Standard("arn:aws:iam::123456789012:user/dev", "my-function", createdAt)
+
+		if !IsManaged(tags) {
+			t.Errorf("expected the tags to mark a managed resource, but received %v", tags)
+		}
+		if tags[TAG_OWNER] != "arn:aws:iam::123456789012:user/dev" || tags[TAG_PROJECT] != "my-function" {
+			t.Errorf("unexpected owner or project in %v", tags)
+		}
+		if tags[TAG_CREATED_AT] != "2024-05-01T17:00:00Z" {
+			t.Errorf("expected created-at in UTC, but received \"%s\"", tags[TAG_CREATED_AT])
+		}
+	})
+
+	t.Run("should not treat untagged resources as managed", func(t *testing.T) {
+		if IsManaged(map[string]string{}) || IsManaged(nil) {
+			t.Errorf("expected untagged resources not to be managed")
+		}
+	})
+
+	t.Run("should convert to IAM tags", func(t *testing.T) {
+		iamTags := ToIAM(map[string]string{TAG_MANAGED: "true"})
+		if len(iamTags) != 1 || *iamTags[0].Key != TAG_MANAGED || *iamTags[0].Value != "true" {
+			t.Errorf("unexpected IAM tags %v", iamTags)
+		}
+	})
+}